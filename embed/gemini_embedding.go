@@ -36,12 +36,12 @@ func ProvideGeminiEmbeddingClient() Embedder {
 }
 
 func (c *GeminiEmbeddingClient) GetEmbedding(ctx context.Context, text string, opts ...EmbedOption) <-chan async.Result[[]float32] {
-	return async.Go(func() ([]float32, error) {
-		cfg := settings{model: "text-embedding-004", taskName: TaskTextMatching}
-		for _, opt := range opts {
-			opt(&cfg)
-		}
+	cfg := settings{model: "text-embedding-004", taskName: TaskTextMatching}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
+	return goTraced(ctx, "gemini", cfg.model, func(ctx context.Context) ([]float32, error) {
 		// Create content from text
 		content := genai.NewContentFromText(text, genai.RoleUser)
 		contents := []*genai.Content{content}
@@ -10,6 +10,7 @@ import (
 	"os"
 
 	"github.com/SaiNageswarS/go-api-boot/logger"
+	"github.com/SaiNageswarS/go-api-boot/requestid"
 	"github.com/SaiNageswarS/go-collection-boot/async"
 )
 
@@ -30,18 +31,18 @@ func ProvideJinaAIEmbeddingClient() Embedder {
 
 	return &JinaAIEmbeddingClient{
 		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		httpClient: &http.Client{Transport: &requestid.Transport{}},
 		url:        "https://api.jina.ai/v1/embeddings",
 	}
 }
 
 func (c *JinaAIEmbeddingClient) GetEmbedding(ctx context.Context, text string, opts ...EmbedOption) <-chan async.Result[[]float32] {
-	return async.Go(func() ([]float32, error) {
-		cfg := settings{model: "jina-embeddings-v4", taskName: TaskRetrievalPassage}
-		for _, opt := range opts {
-			opt(&cfg)
-		}
+	cfg := settings{model: "jina-embeddings-v4", taskName: TaskRetrievalPassage}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
+	return goTraced(ctx, "jina_ai", cfg.model, func(ctx context.Context) ([]float32, error) {
 		req := jinaAIEmbeddingRequest{
 			Model:             cfg.model,
 			Task:              cfg.taskName,
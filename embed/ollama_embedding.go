@@ -31,13 +31,13 @@ func ProvideOllamaEmbeddingClient() Embedder {
 }
 
 func (c *OllamaEmbeddingClient) GetEmbedding(ctx context.Context, text string, opts ...EmbedOption) <-chan async.Result[[]float32] {
-	return async.Go(func() ([]float32, error) {
-		// Default + apply user options
-		cfg := settings{model: "nomic-embed-text"}
-		for _, opt := range opts {
-			opt(&cfg)
-		}
+	// Default + apply user options
+	cfg := settings{model: "nomic-embed-text"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
+	return goTraced(ctx, "ollama", cfg.model, func(ctx context.Context) ([]float32, error) {
 		req := api.EmbeddingRequest{
 			Model:     cfg.model,
 			Prompt:    text,
@@ -0,0 +1,36 @@
+package embed
+
+import (
+	"context"
+
+	"github.com/SaiNageswarS/go-collection-boot/async"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans from this package. The tracer is looked up per
+// span so it follows the global provider that server.WithTracing installs.
+const tracerName = "github.com/SaiNageswarS/go-api-boot/embed"
+
+// goTraced is async.Go with fn running in an "embeddings <model>" span.
+func goTraced(ctx context.Context, provider, model string, fn func(ctx context.Context) ([]float32, error)) <-chan async.Result[[]float32] {
+	return async.Go(func() ([]float32, error) {
+		ctx, span := otel.Tracer(tracerName).Start(ctx, "embeddings "+model,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("gen_ai.operation.name", "embeddings"),
+				attribute.String("gen_ai.system", provider),
+				attribute.String("gen_ai.request.model", model),
+			))
+		defer span.End()
+
+		emb, err := fn(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return emb, err
+	})
+}
@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"context"
 	"os"
 
+	"github.com/SaiNageswarS/go-api-boot/requestid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -46,3 +49,30 @@ var Error = func(msg string, fields ...zap.Field) {
 var Debug = func(msg string, fields ...zap.Field) {
 	Log.Debug(msg, fields...)
 }
+
+// TraceFields returns the trace and span id of the span in ctx, or nothing
+// when ctx carries no span.
+func TraceFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// ContextFields returns the request id and trace fields of ctx.
+func ContextFields(ctx context.Context) []zap.Field {
+	fields := TraceFields(ctx)
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	return fields
+}
+
+// Ctx returns the logger with the request id and trace fields of ctx.
+func Ctx(ctx context.Context) *zap.Logger {
+	return Log.With(ContextFields(ctx)...)
+}
@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/SaiNageswarS/go-api-boot/requestid"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 )
@@ -76,3 +78,26 @@ func waitForLogs(t *testing.T, rec *observer.ObservedLogs, want int) {
 		}
 	}
 }
+
+func TestCtx_AddsContextFields(t *testing.T) {
+	assert.Empty(t, TraceFields(context.Background()))
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	core, rec := observer.New(zap.DebugLevel)
+	orig := Log
+	Log = zap.New(core)
+	defer func() { Log = orig }()
+
+	Ctx(requestid.NewContext(ctx, "req-1")).Info("traced")
+
+	fields := rec.All()[0].ContextMap()
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", fields["span_id"])
+	assert.Equal(t, "req-1", fields["request_id"])
+}
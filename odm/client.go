@@ -2,6 +2,8 @@ package odm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"time"
 
@@ -16,13 +18,41 @@ var mongoConnect = func(uri string) (MongoClient, error) {
 	return mongo.Connect(opts)
 }
 
+// Errors returned by NewMongoClient, which ProvideMongoClient reports with
+// their own Fatal messages.
+var (
+	errNoMongoURI   = errors.New("MONGO_URI environment variable is not set")
+	errMongoConnect = errors.New("failed to connect to MongoDB")
+	errMongoPing    = errors.New("failed to ping MongoDB")
+)
+
+// ProvideMongoClient connects to MONGO_URI for dependency injection and
+// calls logger.Fatal when it can't; see NewMongoClient.
 func ProvideMongoClient() MongoClient {
-	mongoUri := os.Getenv("MONGO_URI")
-	if mongoUri == "" {
+	client, _, err := NewMongoClient()
+	switch {
+	case errors.Is(err, errNoMongoURI):
 		// Providers are designed for dependency injection.
 		// If the MONGO_URI is not set, we log a fatal error.
 		logger.Fatal("MONGO_URI environment variable is not set")
-		return nil // This will never be reached, but it's good practice to return nil here.
+		return nil
+	case errors.Is(err, errMongoPing):
+		logger.Fatal("Failed to ping MongoDB", zap.Error(err))
+		return nil
+	case err != nil:
+		logger.Fatal("Failed to connect to MongoDB", zap.Error(err))
+		return nil
+	}
+	return client
+}
+
+// NewMongoClient is the error-returning variant of ProvideMongoClient for use
+// with server.Builder.ProvideFunc. The returned cleanup disconnects the client
+// and is run by the server on shutdown.
+func NewMongoClient() (MongoClient, func(), error) {
+	mongoUri := os.Getenv("MONGO_URI")
+	if mongoUri == "" {
+		return nil, nil, errNoMongoURI
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -30,14 +60,19 @@ func ProvideMongoClient() MongoClient {
 
 	client, err := mongoConnect(mongoUri)
 	if err != nil {
-		logger.Fatal("Failed to connect to MongoDB", zap.Error(err))
-		return nil
+		return nil, nil, fmt.Errorf("%w: %w", errMongoConnect, err)
 	}
 
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := client.Disconnect(ctx); err != nil {
+			logger.Error("Failed to disconnect MongoDB", zap.Error(err))
+		}
+	}
 	if err := client.Ping(ctx, nil); err != nil {
-		logger.Fatal("Failed to ping MongoDB", zap.Error(err))
-		return nil
+		cleanup() // release the pool Connect started
+		return nil, nil, fmt.Errorf("%w: %w", errMongoPing, err)
 	}
-
-	return client
+	return client, cleanup, nil
 }
@@ -19,6 +19,7 @@ func TestNewMongoConn_PingFails(t *testing.T) {
 	mongoConnect = func(uri string) (MongoClient, error) {
 		mockClient := new(MockMongoClient)
 		mockClient.On("Ping", mock.Anything, mock.Anything).Return(errors.New("ping failed"))
+		mockClient.On("Disconnect", mock.Anything).Return(nil)
 		return mockClient, nil
 	}
 
@@ -76,6 +77,62 @@ func TestGetClient_Failure(t *testing.T) {
 	})
 }
 
+func TestNewMongoClient_EmptyURI(t *testing.T) {
+	testutil.WithEnv("MONGO_URI", "", func(mLog *testutil.MockLogger) {
+		client, cleanup, err := NewMongoClient()
+		assert.Nil(t, client)
+		assert.Nil(t, cleanup)
+		assert.EqualError(t, err, "MONGO_URI environment variable is not set")
+		assert.False(t, mLog.IsFatalCalled)
+	})
+}
+
+func TestNewMongoClient_PingFails(t *testing.T) {
+	mockClient := new(MockMongoClient)
+	mockClient.On("Ping", mock.Anything, mock.Anything).Return(errors.New("ping failed"))
+	mockClient.On("Disconnect", mock.Anything).Return(nil)
+
+	originalMongoConnect := mongoConnect
+	mongoConnect = func(uri string) (MongoClient, error) {
+		return mockClient, nil
+	}
+
+	defer func() {
+		mongoConnect = originalMongoConnect
+	}()
+
+	testutil.WithEnv("MONGO_URI", "mongodb://test:27017", func(mLog *testutil.MockLogger) {
+		_, _, err := NewMongoClient()
+		assert.ErrorContains(t, err, "ping failed")
+		assert.False(t, mLog.IsFatalCalled)
+		mockClient.AssertCalled(t, "Disconnect", mock.Anything)
+	})
+}
+
+func TestNewMongoClient_CleanupDisconnects(t *testing.T) {
+	mockClient := new(MockMongoClient)
+	mockClient.On("Ping", mock.Anything, mock.Anything).Return(nil)
+	mockClient.On("Disconnect", mock.Anything).Return(nil)
+
+	originalMongoConnect := mongoConnect
+	mongoConnect = func(uri string) (MongoClient, error) {
+		return mockClient, nil
+	}
+
+	defer func() {
+		mongoConnect = originalMongoConnect
+	}()
+
+	testutil.WithEnv("MONGO_URI", "mongodb://test:27017", func(mLog *testutil.MockLogger) {
+		client, cleanup, err := NewMongoClient()
+		assert.NoError(t, err)
+		assert.Equal(t, mockClient, client)
+
+		cleanup()
+		mockClient.AssertCalled(t, "Disconnect", mock.Anything)
+	})
+}
+
 type MockMongoClient struct {
 	mock.Mock
 }
@@ -28,6 +28,7 @@ type OdmCollectionInterface[T DbModel] interface {
 type odmCollection[T DbModel] struct {
 	col   CollectionInterface
 	timer Timer
+	db    string // tenant database, for tracing
 }
 
 func CollectionOf[T DbModel](client MongoClient, tenant string) OdmCollectionInterface[T] {
@@ -36,6 +37,7 @@ func CollectionOf[T DbModel](client MongoClient, tenant string) OdmCollectionInt
 	return &odmCollection[T]{
 		col:   client.Database(tenant).Collection(collName),
 		timer: DefaultTimer{},
+		db:    tenant,
 	}
 }
 
@@ -46,7 +48,7 @@ func CollectionOf[T DbModel](client MongoClient, tenant string) OdmCollectionInt
 // lead := db.LeadModel { Name: "Lead1" }
 // _, err := async.Await(odm.CollectionOf[db.LeadModel](s.mongo, tenant).Save(ctx, lead))
 func (c *odmCollection[T]) Save(ctx context.Context, model T) <-chan async.Result[struct{}] {
-	return async.Go(func() (struct{}, error) {
+	return goTraced(ctx, c, "Save", func(ctx context.Context) (struct{}, error) {
 		doc, err := convertToBson(model)
 		if err != nil {
 			return struct{}{}, err
@@ -75,7 +77,7 @@ func (c *odmCollection[T]) FindOneByID(ctx context.Context, id string) <-chan as
 }
 
 func (c *odmCollection[T]) FindOne(ctx context.Context, filters bson.M) <-chan async.Result[*T] {
-	return async.Go(func() (*T, error) {
+	return goTraced(ctx, c, "FindOne", func(ctx context.Context) (*T, error) {
 		if filters == nil {
 			return nil, errors.New("filters cannot be nil for FindOne")
 		}
@@ -91,7 +93,7 @@ func (c *odmCollection[T]) FindOne(ctx context.Context, filters bson.M) <-chan a
 }
 
 func (c *odmCollection[T]) Find(ctx context.Context, filters bson.M, sort bson.D, limit, skip int64) <-chan async.Result[[]T] {
-	return async.Go(func() ([]T, error) {
+	return goTraced(ctx, c, "Find", func(ctx context.Context) ([]T, error) {
 		if filters == nil {
 			filters = bson.M{} // Default to empty filter if none provided
 		}
@@ -119,7 +121,7 @@ func (c *odmCollection[T]) DeleteByID(ctx context.Context, id string) <-chan asy
 }
 
 func (c *odmCollection[T]) DeleteOne(ctx context.Context, filters bson.M) <-chan async.Result[struct{}] {
-	return async.Go(func() (struct{}, error) {
+	return goTraced(ctx, c, "DeleteOne", func(ctx context.Context) (struct{}, error) {
 		if filters == nil {
 			return struct{}{}, errors.New("filters cannot be nil for DeleteOne")
 		}
@@ -130,7 +132,7 @@ func (c *odmCollection[T]) DeleteOne(ctx context.Context, filters bson.M) <-chan
 }
 
 func (c *odmCollection[T]) Count(ctx context.Context, filters bson.M) <-chan async.Result[int64] {
-	return async.Go(func() (int64, error) {
+	return goTraced(ctx, c, "Count", func(ctx context.Context) (int64, error) {
 		return c.col.CountDocuments(ctx, filters)
 	})
 }
@@ -147,17 +149,18 @@ func (c *odmCollection[T]) DistinctInto(ctx context.Context, field string, filte
 		filters = bson.D{} // Default to empty filter if none provided
 	}
 
+	ctx, span := c.startSpan(ctx, "Distinct")
 	res := c.col.Distinct(ctx, field, filters)
-
-	if err := res.Err(); err != nil {
-		return err
+	err := res.Err()
+	if err == nil {
+		err = res.Decode(out)
 	}
-
-	return res.Decode(out)
+	endSpan(span, err)
+	return err
 }
 
 func (c *odmCollection[T]) Aggregate(ctx context.Context, pipeline mongo.Pipeline) <-chan async.Result[[]T] {
-	return async.Go(func() ([]T, error) {
+	return goTraced(ctx, c, "Aggregate", func(ctx context.Context) ([]T, error) {
 		cursor, err := c.col.Aggregate(ctx, pipeline)
 		if err != nil {
 			return nil, err
@@ -169,7 +172,7 @@ func (c *odmCollection[T]) Aggregate(ctx context.Context, pipeline mongo.Pipelin
 }
 
 func (c *odmCollection[T]) Exists(ctx context.Context, id string) <-chan async.Result[bool] {
-	return async.Go(func() (bool, error) {
+	return goTraced(ctx, c, "Exists", func(ctx context.Context) (bool, error) {
 		count, err := c.col.CountDocuments(ctx, bson.M{"_id": id})
 		if err != nil {
 			return false, err
@@ -180,7 +183,7 @@ func (c *odmCollection[T]) Exists(ctx context.Context, id string) <-chan async.R
 
 // VectorSearch performs a vector search using the specified embedding and options.
 func (c *odmCollection[T]) VectorSearch(ctx context.Context, embedding []float32, params VectorSearchParams) <-chan async.Result[[]SearchHit[T]] {
-	return async.Go(func() ([]SearchHit[T], error) {
+	return goTraced(ctx, c, "VectorSearch", func(ctx context.Context) ([]SearchHit[T], error) {
 		if len(embedding) == 0 || params.IndexName == "" || params.Path == "" || params.K <= 0 {
 			return nil, errors.New("invalid input - embedding, index name, path, and K must be provided")
 		}
@@ -220,7 +223,7 @@ func (c *odmCollection[T]) VectorSearch(ctx context.Context, embedding []float32
 }
 
 func (c *odmCollection[T]) TermSearch(ctx context.Context, query string, params TermSearchParams) <-chan async.Result[[]SearchHit[T]] {
-	return async.Go(func() ([]SearchHit[T], error) {
+	return goTraced(ctx, c, "TermSearch", func(ctx context.Context) ([]SearchHit[T], error) {
 		if query == "" || params.IndexName == "" || len(params.Path) == 0 || params.Limit <= 0 {
 			return nil, errors.New("invalid input - query, index name, path, and limit must be provided")
 		}
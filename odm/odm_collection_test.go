@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/SaiNageswarS/go-api-boot/testutil"
 	"github.com/SaiNageswarS/go-collection-boot/async"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 /* ─────────────────────────────
@@ -471,3 +475,35 @@ func toInterface[T any](models []T) []interface{} {
 	}
 	return out
 }
+
+func TestSave_RecordsSpans(t *testing.T) {
+	tp, exporter := testutil.NewInMemoryTracing()
+	orig := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(orig)
+
+	collection := &MockCollection{}
+	repo := &odmCollection[testModel]{col: collection, timer: &MockTimer{}, db: "tenant1"}
+	expectedErr := fmt.Errorf("failed to save")
+	collection.
+		On("UpdateOne", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&mongo.UpdateResult{}, expectedErr)
+	collection.
+		On("CountDocuments", mock.Anything, mock.Anything, mock.Anything).
+		Return(int64(0), nil)
+
+	_, err := async.Await(repo.Save(context.Background(), testModel{Name: "Rick"}))
+	assert.ErrorIs(t, err, expectedErr)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	exists, save := spans[0], spans[1]
+	assert.Equal(t, "Exists test", exists.Name)
+	assert.Equal(t, "Save test", save.Name)
+	assert.Equal(t, save.SpanContext.SpanID(), exists.Parent.SpanID())
+	assert.Equal(t, codes.Error, save.Status.Code)
+	assert.Contains(t, save.Attributes, semconv.DBNamespace("tenant1"))
+	assert.Contains(t, save.Attributes, semconv.DBCollectionName("test"))
+}
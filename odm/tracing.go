@@ -0,0 +1,46 @@
+package odm
+
+import (
+	"context"
+
+	"github.com/SaiNageswarS/go-collection-boot/async"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans from this package. The tracer is looked up per
+// span so it follows the global provider that server.WithTracing installs.
+const tracerName = "github.com/SaiNageswarS/go-api-boot/odm"
+
+func (c *odmCollection[T]) startSpan(ctx context.Context, op string) (context.Context, trace.Span) {
+	var zero T
+	collName := zero.CollectionName()
+	return otel.Tracer(tracerName).Start(ctx, op+" "+collName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMongoDB,
+			semconv.DBNamespace(c.db),
+			semconv.DBCollectionName(collName),
+			semconv.DBOperationName(op),
+		))
+}
+
+// goTraced is async.Go with fn running in a span for op.
+func goTraced[T DbModel, R any](ctx context.Context, c *odmCollection[T], op string, fn func(ctx context.Context) (R, error)) <-chan async.Result[R] {
+	return async.Go(func() (R, error) {
+		ctx, span := c.startSpan(ctx, op)
+		res, err := fn(ctx)
+		endSpan(span, err)
+		return res, err
+	})
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
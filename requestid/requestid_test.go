@@ -0,0 +1,65 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("3f6c1f2e-9a2b-4d3c-8e1f-0a9b8c7d6e5f"))
+	assert.True(t, Valid("req_01:edge.7"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid(strings.Repeat("a", 129)))
+	assert.False(t, Valid("id\nforged-log-line"))
+	assert.False(t, Valid("id with spaces"))
+}
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, FromContext(context.Background()))
+	assert.Equal(t, "req-1", FromContext(NewContext(context.Background(), "req-1")))
+	assert.True(t, Valid(New()))
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	var sent []string
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		sent = md.Get(MetadataKey)
+		return nil
+	}
+	interceptor := UnaryClientInterceptor()
+
+	assert.NoError(t, interceptor(NewContext(context.Background(), "req-1"), "/svc/M", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"req-1"}, sent)
+
+	assert.NoError(t, interceptor(context.Background(), "/svc/M", nil, nil, nil, invoker))
+	assert.Empty(t, sent)
+
+	// an explicit id on the call wins
+	ctx := metadata.AppendToOutgoingContext(NewContext(context.Background(), "req-1"), MetadataKey, "explicit")
+	assert.NoError(t, interceptor(ctx, "/svc/M", nil, nil, nil, invoker))
+	assert.Equal(t, []string{"explicit"}, sent)
+}
+
+func TestTransport(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(Header)
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &Transport{}}
+
+	req, _ := http.NewRequestWithContext(NewContext(context.Background(), "req-1"), http.MethodGet, srv.URL, nil)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "req-1", got)
+	assert.Empty(t, req.Header.Get(Header), "caller's request must not be modified")
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+
+	"github.com/SaiNageswarS/go-api-boot/auth"
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Requirement is what a caller needs to invoke a gRPC method or REST route.
+// A zero Requirement means no authorization check.
+type Requirement struct {
+	// Roles the caller's role (auth.USER_TYPE_CLAIM) must be one of.
+	Roles []string
+	// Permissions the caller must hold, all of them.
+	Permissions []string
+}
+
+func (r Requirement) isZero() bool { return len(r.Roles) == 0 && len(r.Permissions) == 0 }
+
+// RequireRoles requires the caller's role to be one of roles.
+func RequireRoles(roles ...string) Requirement { return Requirement{Roles: roles} }
+
+// RequirePermissions requires the caller to hold every permission.
+func RequirePermissions(perms ...string) Requirement { return Requirement{Permissions: perms} }
+
+// AuthzRequest is the input of an AuthzPolicy.
+type AuthzRequest struct {
+	// Method is the full gRPC method, or "<HTTP method> <pattern>" for REST.
+	Method      string
+	Requirement Requirement
+
+	UserID string
+	Tenant string
+	Role   string
+
+	// Request is the gRPC request message (nil for streams) or the *http.Request,
+	// e.g. to compare the tenant it addresses with Tenant.
+	Request any
+}
+
+// AuthzPolicy decides whether a caller meets a Requirement. A non-nil error
+// denies the call with codes.PermissionDenied / 403; its message is audited,
+// not returned to the caller.
+type AuthzPolicy func(ctx context.Context, req AuthzRequest) error
+
+// RolePolicy is the default policy. It checks Roles against the caller's role
+// and Permissions against the permissions rolePermissions grants that role.
+//
+// Example:
+//
+//	builder.AuthorizationPolicy(server.RolePolicy(map[string][]string{
+//	    "admin":   {"orders:read", "orders:cancel"},
+//	    "support": {"orders:read"},
+//	}))
+func RolePolicy(rolePermissions map[string][]string) AuthzPolicy {
+	return func(_ context.Context, req AuthzRequest) error {
+		if len(req.Requirement.Roles) > 0 && !slices.Contains(req.Requirement.Roles, req.Role) {
+			return fmt.Errorf("role %q is not one of %v", req.Role, req.Requirement.Roles)
+		}
+		for _, p := range req.Requirement.Permissions {
+			if !slices.Contains(rolePermissions[req.Role], p) {
+				return fmt.Errorf("role %q lacks permission %q", req.Role, p)
+			}
+		}
+		return nil
+	}
+}
+
+// authzRule maps a method pattern to its requirement.
+type authzRule struct {
+	pattern string
+	req     Requirement
+}
+
+// authorizer evaluates the policy for methods and routes with a requirement.
+type authorizer struct {
+	exact  map[string]Requirement
+	globs  []authzRule // checked in registration order
+	policy AuthzPolicy
+}
+
+func newAuthorizer() *authorizer {
+	return &authorizer{exact: map[string]Requirement{}, policy: RolePolicy(nil)}
+}
+
+func (a *authorizer) add(pattern string, req Requirement) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	if isGlob(pattern) {
+		a.globs = append(a.globs, authzRule{pattern: pattern, req: req})
+	} else {
+		a.exact[pattern] = req
+	}
+	return nil
+}
+
+func (a *authorizer) empty() bool { return len(a.exact) == 0 && len(a.globs) == 0 }
+
+func (a *authorizer) requirement(fullMethod string) (Requirement, bool) {
+	if r, ok := a.exact[fullMethod]; ok {
+		return r, true
+	}
+	for _, g := range a.globs {
+		if ok, _ := path.Match(g.pattern, fullMethod); ok {
+			return g.req, true
+		}
+	}
+	return Requirement{}, false
+}
+
+var errPermissionDenied = errors.New("permission denied")
+
+// check runs the policy and audits denials.
+func (a *authorizer) check(ctx context.Context, method string, req Requirement, request any) error {
+	userID, tenant := auth.GetUserIdAndTenant(ctx)
+	in := AuthzRequest{
+		Method:      method,
+		Requirement: req,
+		UserID:      userID,
+		Tenant:      tenant,
+		Role:        auth.GetUserType(ctx),
+		Request:     request,
+	}
+	err := a.policy(ctx, in)
+	if err == nil {
+		return nil
+	}
+
+	fields := []zap.Field{
+		zap.String("audit", "authz.denied"),
+		zap.String("method", method),
+		zap.String("userId", in.UserID),
+		zap.String("tenant", in.Tenant),
+		zap.String("role", in.Role),
+		zap.Strings("requiredRoles", req.Roles),
+		zap.Strings("requiredPermissions", req.Permissions),
+		zap.Error(err),
+	}
+	logger.Info("Authorization denied", append(fields, logger.ContextFields(ctx)...)...)
+	return errPermissionDenied
+}
+
+func (a *authorizer) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if r, ok := a.requirement(info.FullMethod); ok {
+			if err := a.check(ctx, info.FullMethod, r, req); err != nil {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *authorizer) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if r, ok := a.requirement(info.FullMethod); ok {
+			if err := a.check(ss.Context(), info.FullMethod, r, nil); err != nil {
+				return status.Error(codes.PermissionDenied, err.Error())
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// middleware verifies the bearer token of a route with a requirement (401 when
+// missing or invalid) and then applies the policy (403 when denied).
+func (a *authorizer) middleware(route Route, next http.Handler) http.Handler {
+	if route.Require.isZero() {
+		return next
+	}
+	method := route.Method + " " + route.Pattern
+	if route.Method == "" {
+		method = "* " + route.Pattern
+	}
+	return auth.VerifyTokenHttpMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.check(r.Context(), method, route.Require, r); err != nil {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SaiNageswarS/go-api-boot/auth"
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func claimsCtx(userID, tenant, role string) context.Context {
+	ctx := context.WithValue(context.Background(), auth.USER_ID_CLAIM, userID)
+	ctx = context.WithValue(ctx, auth.TENANT_CLAIM, tenant)
+	return context.WithValue(ctx, auth.USER_TYPE_CLAIM, role)
+}
+
+// captureInfo records logger.Info messages for the duration of the test.
+func captureInfo(t *testing.T) *[]string {
+	t.Helper()
+	var msgs []string
+	orig := logger.Info
+	logger.Info = func(msg string, fields ...zap.Field) { msgs = append(msgs, msg) }
+	t.Cleanup(func() { logger.Info = orig })
+	return &msgs
+}
+
+func TestRolePolicy(t *testing.T) {
+	policy := RolePolicy(map[string][]string{"support": {"orders:read"}})
+	check := func(role string, req Requirement) error {
+		return policy(context.Background(), AuthzRequest{Role: role, Requirement: req})
+	}
+
+	assert.NoError(t, check("admin", RequireRoles("admin", "owner")))
+	assert.Error(t, check("user", RequireRoles("admin")))
+	assert.NoError(t, check("support", RequirePermissions("orders:read")))
+	assert.Error(t, check("support", RequirePermissions("orders:read", "orders:cancel")))
+	assert.Error(t, check("", RequirePermissions("orders:read")))
+}
+
+func TestAuthorizer_UnaryInterceptor(t *testing.T) {
+	audit := captureInfo(t)
+	a := newAuthorizer()
+	assert.NoError(t, a.add("/admin.Users/*", RequireRoles("admin")))
+	assert.NoError(t, a.add("/admin.Users/Get", RequireRoles("admin", "support")))
+	interceptor := a.unaryInterceptor()
+
+	call := func(ctx context.Context, method string) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, any) (any, error) { return "ok", nil })
+		return err
+	}
+
+	assert.NoError(t, call(claimsCtx("u1", "t1", "admin"), "/admin.Users/Delete"))
+	assert.NoError(t, call(claimsCtx("u2", "t1", "support"), "/admin.Users/Get")) // exact rule wins
+	assert.NoError(t, call(claimsCtx("u3", "t1", "user"), "/shop.Orders/List"))   // no rule
+
+	err := call(claimsCtx("u2", "t1", "support"), "/admin.Users/Delete")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, []string{"Authorization denied"}, *audit)
+}
+
+func TestAuthorizer_StreamInterceptor(t *testing.T) {
+	captureInfo(t)
+	a := newAuthorizer()
+	assert.NoError(t, a.add("/admin.Users/Watch", RequireRoles("admin")))
+
+	err := a.streamInterceptor()(nil, &scopeTestStream{ctx: claimsCtx("u1", "t1", "user")},
+		&grpc.StreamServerInfo{FullMethod: "/admin.Users/Watch"},
+		func(any, grpc.ServerStream) error { return nil })
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// tenantRequest addresses a tenant, like a generated request message.
+type tenantRequest struct{ tenant string }
+
+func (r *tenantRequest) GetTenant() string { return r.tenant }
+
+func TestAuthorizer_CustomPolicyWithTenantCheck(t *testing.T) {
+	captureInfo(t)
+	a := newAuthorizer()
+	assert.NoError(t, a.add("/shop.Orders/List", RequireRoles("admin")))
+	a.policy = func(ctx context.Context, req AuthzRequest) error {
+		if err := RolePolicy(nil)(ctx, req); err != nil {
+			return err
+		}
+		if r, ok := req.Request.(interface{ GetTenant() string }); ok && r.GetTenant() != req.Tenant {
+			return errors.New("cross-tenant access")
+		}
+		return nil
+	}
+	interceptor := a.unaryInterceptor()
+	call := func(req any) error {
+		_, err := interceptor(claimsCtx("u1", "acme", "admin"), req, &grpc.UnaryServerInfo{FullMethod: "/shop.Orders/List"},
+			func(context.Context, any) (any, error) { return nil, nil })
+		return err
+	}
+
+	assert.NoError(t, call(&tenantRequest{tenant: "acme"}))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(&tenantRequest{tenant: "globex"})))
+}
+
+// adminController has one protected and one open route.
+type adminController struct{}
+
+func (c *adminController) Routes() []Route {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	return []Route{
+		{Pattern: "/admin/stats", Method: http.MethodGet, Handler: ok, Require: RequireRoles("admin")},
+		{Pattern: "/admin/ping", Method: http.MethodGet, Handler: ok},
+	}
+}
+
+func TestBuilder_RouteRequire(t *testing.T) {
+	captureInfo(t)
+	t.Setenv("ACCESS-SECRET", "test-secret")
+	adminToken, _ := auth.GetToken("t1", "u1", "admin")
+	userToken, _ := auth.GetToken("t1", "u2", "user")
+
+	bs, err := New().
+		HTTPPort(":0").
+		AddRestController(func() *adminController { return &adminController{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		bs.http.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/admin/stats", ""))
+	assert.Equal(t, http.StatusForbidden, get("/admin/stats", userToken))
+	assert.Equal(t, http.StatusOK, get("/admin/stats", adminToken))
+	assert.Equal(t, http.StatusOK, get("/admin/ping", ""))
+}
+
+func TestBuilder_Authorize_InvalidPattern(t *testing.T) {
+	mockLogger := withMockLogger(func() {
+		New().Authorize("/admin.Users/[", RequireRoles("admin"))
+	})
+	assert.True(t, mockLogger.isFatalCalled)
+	assert.Equal(t, "Invalid authorization pattern", mockLogger.fatalMsg)
+}
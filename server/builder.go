@@ -7,20 +7,27 @@ import (
 	"net"
 	"net/http"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/SaiNageswarS/go-api-boot/auth"
 	"github.com/SaiNageswarS/go-api-boot/logger"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
 	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 // ─── public fluent builder ───────────────────────────────────
@@ -36,13 +43,69 @@ type Builder struct {
 
 	singletons map[reflect.Type]reflect.Value
 	providers  map[reflect.Type]reflect.Value
+	lifetimes  map[reflect.Type]Lifetime
+	groups     map[reflect.Type][]groupMember
 	reg        []registration
 
+	// named registrations, resolved through Named[T, Tag] parameters
+	named          map[namedKey]reflect.Value
+	namedProviders map[namedKey]reflect.Value
+
 	// REST controller registrations
 	restControllerRegs []reflect.Value
 
 	serverOpts []grpc.ServerOption
 
+	// HTTP server limits; zero values fall back to net/http defaults
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	maxBodyBytes      int64 // 0 ⇒ unlimited
+
+	// shutdown grace periods
+	shutdownTimeout  time.Duration
+	grpcDrainTimeout time.Duration // 0 ⇒ wait for in-flight RPCs indefinitely
+
+	// per-hook timeout for Starter/Stopper components
+	lifecycleTimeout time.Duration
+
+	// names of installed modules
+	installed map[string]bool
+
+	// serve the DI graph at /debug/di
+	debugGraph bool
+
+	// methods that skip token verification
+	publicMethods *methodSet
+
+	// role / permission checks for gRPC methods and REST routes
+	authz *authorizer
+
+	// per-tenant / user / IP limits for gRPC methods and REST routes
+	rateLimits *rateLimiter
+
+	// request and panic metrics; filled in by Build from the Registerer
+	metrics *requestMetrics
+
+	// HTTP middleware for REST routes, static files and the web proxy
+	middleware []Middleware
+
+	// path the web proxy serves /{service}/{method} under; "" ⇒ the root
+	webProxyPrefix string
+
+	// idle time before an SSE heartbeat; 0 ⇒ 15s
+	sseHeartbeat time.Duration
+	// accept the SSE access_token query parameter as a bearer token
+	sseQueryToken bool
+
+	// serve google.api.http bindings of registered services
+	transcoding bool
+
+	// OpenTelemetry; nil ⇒ tracing off
+	tracerProvider trace.TracerProvider
+
 	// temporal worker for DI
 	taskQueue          string
 	activityRegs       []reflect.Value
@@ -56,25 +119,49 @@ type registration struct {
 }
 
 func New() *Builder {
+	public := newMethodSet()
+	metrics := &requestMetrics{} // the collectors are registered by Build
 	return &Builder{
-		cors:       cors.AllowAll(),
-		singletons: map[reflect.Type]reflect.Value{},
-		providers:  map[reflect.Type]reflect.Value{},
+		cors:            cors.AllowAll(),
+		singletons:      map[reflect.Type]reflect.Value{},
+		providers:       map[reflect.Type]reflect.Value{},
+		lifetimes:       map[reflect.Type]Lifetime{},
+		groups:          map[reflect.Type][]groupMember{},
+		named:           map[namedKey]reflect.Value{},
+		namedProviders:  map[namedKey]reflect.Value{},
+		installed:       map[string]bool{},
+		publicMethods:   public,
+		authz:           newAuthorizer(),
+		rateLimits:      newRateLimiter(metrics),
+		readTimeout:     5 * time.Minute,
+		writeTimeout:    5 * time.Minute,
+		idleTimeout:     10 * time.Minute,
+		shutdownTimeout: 5 * time.Second,
+		webProxyPrefix:  "/api",
+		metrics:         metrics,
 		unary: []grpc.UnaryServerInterceptor{
 			grpc_ctxtags.UnaryServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
+			requestIDUnaryInterceptor(),
 			grpc_zap.UnaryServerInterceptor(logger.Get()),
-			grpc_auth.UnaryServerInterceptor(auth.VerifyTokenGrpcMiddleware()),
+			recoveryUnaryInterceptor(metrics),
+			authUnaryInterceptor(public, auth.VerifyTokenGrpcMiddleware()),
 		},
 		stream: []grpc.StreamServerInterceptor{
 			grpc_ctxtags.StreamServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
+			requestIDStreamInterceptor(),
 			grpc_zap.StreamServerInterceptor(logger.Get()),
-			grpc_auth.StreamServerInterceptor(auth.VerifyTokenGrpcMiddleware()),
+			recoveryStreamInterceptor(metrics),
+			authStreamInterceptor(public, auth.VerifyTokenGrpcMiddleware()),
 		},
 	}
 }
 
 // ----- basic wiring ----------------------------------------------------------
 
+// GRPCPort and HTTPPort are each optional: leave one unset for an HTTP-only or
+// gRPC-only service, or both for a Temporal worker. With only HTTPPort, gRPC
+// services are still reachable through the web proxy at /api; a worker
+// that sets HTTPPort exposes /health and /metrics.
 func (b *Builder) GRPCPort(p string) *Builder { b.grpcPort = p; return b }
 func (b *Builder) HTTPPort(p string) *Builder { b.httpPort = p; return b }
 
@@ -94,6 +181,177 @@ func (b *Builder) Stream(i ...grpc.StreamServerInterceptor) *Builder {
 }
 func (b *Builder) CORS(c *cors.Cors) *Builder { b.cors = c; return b }
 
+// WebProxyPrefix sets the path the gRPC-Web and Connect proxy serves
+// /{service}/{method} under, and the discovery endpoint {prefix}/services
+// lists. Defaults to "/api"; "" serves services at the root. Only the paths
+// of registered services are claimed, so REST routes can share the prefix.
+func (b *Builder) WebProxyPrefix(prefix string) *Builder {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" && (!strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, "{} ")) {
+		logger.Fatal("Invalid web proxy prefix", zap.String("prefix", prefix))
+	}
+	b.webProxyPrefix = prefix
+	return b
+}
+
+// Use adds HTTP middleware to every REST route, static file and gRPC-Web
+// request, the first one outermost. It runs after CORS, request ids, tracing,
+// metrics and panic recovery, and before the body limit and the controller and
+// route middleware. /health, /livez, /readyz and /metrics are not wrapped.
+func (b *Builder) Use(mw ...Middleware) *Builder {
+	for _, m := range mw {
+		if m == nil {
+			logger.Fatal("middleware must not be nil")
+		}
+	}
+	b.middleware = append(b.middleware, mw...)
+	return b
+}
+
+// LifecycleTimeout bounds each Start/Stop hook of DI-managed components.
+// Defaults to 30 seconds.
+func (b *Builder) LifecycleTimeout(d time.Duration) *Builder { b.lifecycleTimeout = d; return b }
+
+// ReadTimeout, ReadHeaderTimeout, WriteTimeout, IdleTimeout and
+// MaxHeaderBytes configure the HTTP server. Defaults: 5m read and write,
+// 10m idle; ReadHeaderTimeout and MaxHeaderBytes use the net/http defaults.
+func (b *Builder) ReadTimeout(d time.Duration) *Builder       { b.readTimeout = d; return b }
+func (b *Builder) ReadHeaderTimeout(d time.Duration) *Builder { b.readHeaderTimeout = d; return b }
+func (b *Builder) WriteTimeout(d time.Duration) *Builder      { b.writeTimeout = d; return b }
+func (b *Builder) IdleTimeout(d time.Duration) *Builder       { b.idleTimeout = d; return b }
+func (b *Builder) MaxHeaderBytes(n int) *Builder              { b.maxHeaderBytes = n; return b }
+
+// SSEHeartbeat sets how often an idle Server-Sent Events stream gets a
+// heartbeat comment, which keeps proxies from closing it. Defaults to 15
+// seconds.
+func (b *Builder) SSEHeartbeat(d time.Duration) *Builder {
+	if d <= 0 {
+		logger.Fatal("SSE heartbeat must be positive")
+	}
+	b.sseHeartbeat = d
+	return b
+}
+
+// SSEQueryToken lets SSE subscribers authenticate with an access_token query
+// parameter, since an EventSource can't set an Authorization header. The
+// parameter is removed from the URL before any middleware or the gRPC call
+// sees it, but query strings still reach browser history and the access logs
+// of proxies in front of the server, so only use it with short-lived tokens.
+// Off by default; the parameter is then dropped and ignored.
+func (b *Builder) SSEQueryToken() *Builder { b.sseQueryToken = true; return b }
+
+// MaxBodyBytes limits request bodies on REST routes and the web proxy. Requests with a
+// larger Content-Length are rejected with 413; reading a chunked body past the
+// limit fails with *http.MaxBytesError. Unlimited by default.
+func (b *Builder) MaxBodyBytes(n int64) *Builder { b.maxBodyBytes = n; return b }
+
+// ShutdownTimeout bounds how long Serve waits for in-flight HTTP requests
+// after its context is cancelled. Defaults to 5 seconds.
+func (b *Builder) ShutdownTimeout(d time.Duration) *Builder { b.shutdownTimeout = d; return b }
+
+// GRPCDrainTimeout bounds how long in-flight RPCs and streams may run after
+// shutdown starts; the remaining ones are then closed with Stop. By default
+// GracefulStop waits for all of them.
+func (b *Builder) GRPCDrainTimeout(d time.Duration) *Builder { b.grpcDrainTimeout = d; return b }
+
+// PublicMethods lets the listed gRPC methods run without a token. Entries are
+// full method names or path.Match globs, e.g. "/auth.Login/Login" or
+// "/auth.Login/*". A token sent to a public method is still verified and its
+// claims added to the context; an invalid one leaves the request anonymous.
+// Services can still opt out entirely with AuthFuncOverride.
+func (b *Builder) PublicMethods(patterns ...string) *Builder {
+	for _, p := range patterns {
+		if err := b.publicMethods.add(p); err != nil {
+			logger.Fatal("Invalid public method pattern", zap.String("pattern", p), zap.Error(err))
+		}
+	}
+	return b
+}
+
+// Authorize requires callers of the gRPC methods matching pattern (a full
+// method name or path.Match glob) to meet req. The first exact match wins,
+// then globs in registration order. Methods without a rule are not checked.
+// REST routes declare their requirement in Route.Require.
+//
+// Example:
+//
+//	builder.
+//	    Authorize("/admin.Users/*", server.RequireRoles("admin")).
+//	    Authorize("/shop.Orders/Cancel", server.RequirePermissions("orders:cancel"))
+func (b *Builder) Authorize(pattern string, req Requirement) *Builder {
+	if err := b.authz.add(pattern, req); err != nil {
+		logger.Fatal("Invalid authorization pattern", zap.String("pattern", pattern), zap.Error(err))
+	}
+	return b
+}
+
+// RateLimit limits calls to the gRPC methods matching pattern (a full method
+// name or path.Match glob); methods matching one pattern share its budget. The
+// first exact match wins, then globs in registration order. Rejected calls get
+// codes.ResourceExhausted with a retry-after header and RetryInfo detail. REST
+// routes declare their limit in Route.RateLimit.
+//
+// Example:
+//
+//	builder.
+//	    RateLimit("/shop.*/*", server.RateLimit{Key: server.RateLimitByTenant, Limit: 1000, Window: time.Minute}).
+//	    RateLimit("/auth.Login/Login", server.RateLimit{Key: server.RateLimitByIP, Strategy: server.SlidingWindow, Limit: 5, Window: time.Minute})
+func (b *Builder) RateLimit(pattern string, limit RateLimit) *Builder {
+	if err := b.rateLimits.add(pattern, limit); err != nil {
+		logger.Fatal("Invalid rate limit", zap.String("pattern", pattern), zap.Error(err))
+	}
+	return b
+}
+
+// RateLimitStore replaces the in-memory store, e.g. with one backed by Redis
+// so limits hold across replicas.
+func (b *Builder) RateLimitStore(s RateLimitStore) *Builder {
+	if s == nil {
+		logger.Fatal("rate limit store must not be nil")
+	}
+	b.rateLimits.store = s
+	return b
+}
+
+// AuthorizationPolicy replaces the default RolePolicy(nil), which only checks
+// roles. Denied calls get codes.PermissionDenied / 403 and are audit-logged.
+func (b *Builder) AuthorizationPolicy(p AuthzPolicy) *Builder { b.authz.policy = p; return b }
+
+// WithTracing turns on OpenTelemetry tracing with tp: spans for gRPC methods,
+// REST routes, the web proxy, ODM operations, embedder calls and Temporal workflows and
+// activities, with W3C trace context propagation. Build installs tp and the
+// propagator globally and provides tp as a trace.TracerProvider.
+//
+// Example:
+//
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+//	builder.WithTracing(tp)
+func (b *Builder) WithTracing(tp trace.TracerProvider) *Builder {
+	if tp == nil {
+		logger.Fatal("tracer provider must not be nil")
+	}
+	b.tracerProvider = tp
+	return b
+}
+
+// DebugGraph serves the resolved dependency graph at /debug/di as JSON, or as
+// Graphviz DOT with ?format=dot. Keep it off in production or behind auth.
+func (b *Builder) DebugGraph() *Builder { b.debugGraph = true; return b }
+
+// EnableHTTPTranscoding exposes every unary method with a google.api.http
+// option as a JSON route on the HTTP port, e.g.
+//
+//	rpc GetBook(GetBookRequest) returns (Book) {
+//	    option (google.api.http) = { get: "/v1/{name=shelves/*/books/*}" };
+//	}
+//
+// serves GET /v1/shelves/1/books/2. Path variables, query parameters and the
+// body are bound with protojson and the call runs in-process through the gRPC
+// server, so every interceptor – auth, authorization, rate limits – applies.
+// Headers are forwarded as metadata. Errors use the WriteJSONError envelope.
+// A binding whose route conflicts with another route fails Build.
+func (b *Builder) EnableHTTPTranscoding() *Builder { b.transcoding = true; return b }
+
 // AddRestController registers a REST controller factory for dependency injection.
 // The factory is a function that takes dependencies as arguments and returns
 // a type implementing RestController interface.
@@ -167,13 +425,157 @@ func (b *Builder) ProvideAs(value any, ifacePtr any) *Builder {
 	return b
 }
 
+// ProvideFunc registers a lazy singleton provider. Supported signatures are
+// func(...) T, func(...) (T, error) and func(...) (T, func(), error).
+// A returned error aborts Build; a returned cleanup func runs in reverse
+// order during shutdown (or when Build fails later on).
 func (b *Builder) ProvideFunc(fn any) *Builder {
+	return b.provideFunc("ProvideFunc", fn, Singleton)
+}
+
+// ProvideTransient registers a provider that runs for every consumer of its type.
+func (b *Builder) ProvideTransient(fn any) *Builder {
+	return b.provideFunc("ProvideTransient", fn, Transient)
+}
+
+// ProvideScoped registers a provider that runs at most once per gRPC / HTTP
+// request. The value is read inside handlers with server.Resolve[T](ctx) and
+// any cleanup func runs when the request completes. Scoped providers may take
+// a context.Context parameter to receive the request context (auth claims,
+// tenant, ...).
+//
+// Example:
+//
+//	builder.ProvideScoped(func(ctx context.Context, mongo odm.MongoClient) odm.OdmCollectionInterface[db.ProfileModel] {
+//	    _, tenant := auth.GetUserIdAndTenant(ctx)
+//	    return odm.CollectionOf[db.ProfileModel](mongo, tenant)
+//	})
+func (b *Builder) ProvideScoped(fn any) *Builder {
+	return b.provideFunc("ProvideScoped", fn, Scoped)
+}
+
+func (b *Builder) provideFunc(caller string, fn any, lifetime Lifetime) *Builder {
 	v := reflect.ValueOf(fn)
 	if v.Kind() != reflect.Func {
-		logger.Fatal("ProvideFunc expects a function", zap.Any("received", fn))
+		logger.Fatal(caller+" expects a function", zap.Any("received", fn))
+	}
+	if !isProviderFunc(v.Type()) {
+		logger.Fatal(caller+" expects func(...) T, func(...) (T, error) or func(...) (T, func(), error)",
+			zap.String("signature", v.Type().String()))
 	}
 	out := v.Type().Out(0)
 	b.providers[out] = v
+	if lifetime == Singleton {
+		delete(b.lifetimes, out)
+	} else {
+		b.lifetimes[out] = lifetime
+	}
+	return b
+}
+
+// ProvideInto contributes value to the group of ifacePtr's element type.
+// Factories taking a []T parameter receive every contribution to T, in
+// registration order.
+//
+// Example:
+//
+//	builder.
+//	    ProvideInto(mongoChecker, (*HealthChecker)(nil)).
+//	    ProvideInto(temporalChecker, (*HealthChecker)(nil)).
+//	    AddRestController(func(checks []HealthChecker) *StatusController { ... })
+func (b *Builder) ProvideInto(value any, ifacePtr any) *Builder {
+	elem := reflect.TypeOf(ifacePtr).Elem()
+	val := reflect.ValueOf(value)
+
+	if !val.Type().AssignableTo(elem) {
+		logger.Fatal("Provided value cannot be added to the group",
+			zap.String("valueType", val.Type().String()),
+			zap.String("groupType", "[]"+elem.String()))
+	}
+
+	b.groups[elem] = append(b.groups[elem], groupMember{value: val})
+	return b
+}
+
+// ProvideFuncInto contributes the result of provider fn to the group of
+// ifacePtr's element type. fn accepts the same signatures as ProvideFunc.
+func (b *Builder) ProvideFuncInto(fn any, ifacePtr any) *Builder {
+	elem := reflect.TypeOf(ifacePtr).Elem()
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		logger.Fatal("ProvideFuncInto expects a function", zap.Any("received", fn))
+	}
+	if !isProviderFunc(v.Type()) {
+		logger.Fatal("ProvideFuncInto expects func(...) T, func(...) (T, error) or func(...) (T, func(), error)",
+			zap.String("signature", v.Type().String()))
+	}
+	if !v.Type().Out(0).AssignableTo(elem) {
+		logger.Fatal("Provider result cannot be added to the group",
+			zap.String("valueType", v.Type().Out(0).String()),
+			zap.String("groupType", "[]"+elem.String()))
+	}
+
+	b.groups[elem] = append(b.groups[elem], groupMember{provider: v})
+	return b
+}
+
+// AddHealthCheck registers readiness checks run by /readyz and the
+// grpc.health.v1 Health service.
+func (b *Builder) AddHealthCheck(checkers ...HealthChecker) *Builder {
+	for _, c := range checkers {
+		b.ProvideInto(c, (*HealthChecker)(nil))
+	}
+	return b
+}
+
+// AddHealthCheckFunc registers a readiness check built by a DI provider,
+// e.g. MongoHealthCheck. fn accepts the same signatures as ProvideFunc.
+func (b *Builder) AddHealthCheckFunc(fn any) *Builder {
+	return b.ProvideFuncInto(fn, (*HealthChecker)(nil))
+}
+
+// ProvideNamed registers value under name. Consumers request it with a
+// Named[T, Tag] parameter whose Tag returns the same name.
+func (b *Builder) ProvideNamed(name string, value any) *Builder {
+	if name == "" {
+		logger.Fatal("ProvideNamed expects a non-empty name")
+	}
+	b.named[namedKey{name: name, typ: reflect.TypeOf(value)}] = reflect.ValueOf(value)
+	return b
+}
+
+// ProvideNamedAs is ProvideAs for a named registration.
+func (b *Builder) ProvideNamedAs(name string, value any, ifacePtr any) *Builder {
+	if name == "" {
+		logger.Fatal("ProvideNamedAs expects a non-empty name")
+	}
+	ifaceType := reflect.TypeOf(ifacePtr).Elem()
+	val := reflect.ValueOf(value)
+
+	if !val.Type().Implements(ifaceType) {
+		logger.Fatal("Provided value does not implement the given interface",
+			zap.String("valueType", val.Type().String()),
+			zap.String("interfaceType", ifaceType.String()))
+	}
+
+	b.named[namedKey{name: name, typ: ifaceType}] = val
+	return b
+}
+
+// ProvideFuncNamed is ProvideFunc for a named registration.
+func (b *Builder) ProvideFuncNamed(name string, fn any) *Builder {
+	if name == "" {
+		logger.Fatal("ProvideFuncNamed expects a non-empty name")
+	}
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		logger.Fatal("ProvideFuncNamed expects a function", zap.Any("received", fn))
+	}
+	if !isProviderFunc(v.Type()) {
+		logger.Fatal("ProvideFuncNamed expects func(...) T, func(...) (T, error) or func(...) (T, func(), error)",
+			zap.String("signature", v.Type().String()))
+	}
+	b.namedProviders[namedKey{name: name, typ: v.Type().Out(0)}] = v
 	return b
 }
 
@@ -201,82 +603,239 @@ func (b *Builder) ApplySettings(opts []grpc.ServerOption) *Builder {
 
 // ----- Resolve DI and build servers/workers -----------------------------------------------------
 
-func (b *Builder) Build() (*BootServer, error) {
-	if b.grpcPort == "" || b.httpPort == "" {
-		return nil, errors.New("grpc and http ports must be set")
+func (b *Builder) Build() (_ *BootServer, err error) {
+	if b.grpcPort == "" && b.httpPort == "" && b.temporalClientOpts == nil {
+		return nil, errors.New("at least one of grpc port, http port or temporal worker must be configured")
+	}
+	if b.sslProvider != nil && b.httpPort == "" {
+		return nil, errors.New("ssl requires an http port")
 	}
 
-	lnGrpc, err := net.Listen("tcp", b.grpcPort)
-	if err != nil {
+	// tiny DI container
+	ctn := newContainer(b.singletons, b.providers)
+	ctn.lifetimes, ctn.groups = b.lifetimes, b.groups
+	ctn.named, ctn.namedProviders = b.named, b.namedProviders
+
+	// metrics go to the default registry unless a Registerer is provided
+	if _, ok := b.singletons[registererType]; !ok {
+		if _, ok := b.providers[registererType]; !ok {
+			b.singletons[registererType] = reflect.ValueOf(&prometheus.DefaultRegisterer).Elem()
+		}
+	}
+
+	if b.tracerProvider != nil {
+		otel.SetTracerProvider(b.tracerProvider)
+		otel.SetTextMapPropagator(tracePropagator)
+		if _, ok := b.singletons[tracerProviderType]; !ok {
+			b.singletons[tracerProviderType] = reflect.ValueOf(&b.tracerProvider).Elem()
+		}
+	}
+
+	// report every missing dependency and cycle before anything is constructed
+	if err := b.validateGraph(ctn); err != nil {
 		return nil, err
 	}
-	lnHTTP, err := net.Listen("tcp", b.httpPort)
+
+	var lnGrpc, lnHTTP net.Listener
+	var tc client.Client
+	// abort cleanly: release listeners, the temporal client and whatever
+	// providers were already built
+	defer func() {
+		if err == nil {
+			return
+		}
+		if tc != nil {
+			tc.Close()
+		}
+		ctn.runCleanups()
+		if lnGrpc != nil {
+			lnGrpc.Close()
+		}
+		if lnHTTP != nil {
+			lnHTTP.Close()
+		}
+	}()
+
+	// each listener is optional: worker-only and HTTP-only services skip the rest
+	if b.grpcPort != "" {
+		if lnGrpc, err = net.Listen("tcp", b.grpcPort); err != nil {
+			return nil, err
+		}
+	}
+	if b.httpPort != "" {
+		if lnHTTP, err = net.Listen("tcp", b.httpPort); err != nil {
+			return nil, err
+		}
+	}
+
+	regVal, err := ctn.resolve(registererType)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("metrics DI failed: %w", err)
+	}
+	reg := regVal.Interface().(prometheus.Registerer)
+	rm, err := newRequestMetrics(reg)
+	if err != nil {
+		return nil, fmt.Errorf("metrics registration failed: %w", err)
+	}
+	*b.metrics = *rm // the default interceptors and the rate limiter hold b.metrics
+	metrics, err := metricsHandler(reg)
+	if err != nil {
+		return nil, fmt.Errorf("metrics registration failed: %w", err)
 	}
 
-	// Prepare server options
+	// Prepare server options; metrics wrap everything, rate limits and then
+	// authorization run after every user interceptor and the request scope is
+	// the innermost interceptor
+	unary := append([]grpc.UnaryServerInterceptor{rm.unaryInterceptor()}, b.unary...)
+	stream := append([]grpc.StreamServerInterceptor{rm.streamInterceptor()}, b.stream...)
+	if b.tracerProvider != nil {
+		unary = append(unary, traceTagsUnaryInterceptor())
+		stream = append(stream, traceTagsStreamInterceptor())
+		b.serverOpts = append(b.serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(b.tracerProvider))))
+	}
+	if !b.rateLimits.empty() {
+		unary = append(unary, b.rateLimits.unaryInterceptor())
+		stream = append(stream, b.rateLimits.streamInterceptor())
+	}
+	if !b.authz.empty() {
+		unary = append(unary, b.authz.unaryInterceptor())
+		stream = append(stream, b.authz.streamInterceptor())
+	}
+	unary = append(unary, scopeUnaryInterceptor(ctn))
+	stream = append(stream, scopeStreamInterceptor(ctn))
 	b.serverOpts = append(b.serverOpts,
-		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(b.stream...)),
-		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(b.unary...)),
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(stream...)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unary...)),
 	)
 
 	grpcSrv := grpc.NewServer(b.serverOpts...)
 
-	// tiny DI container
-	ctn := newContainer(b.singletons, b.providers)
-
 	// register services
 	for _, r := range b.reg {
-		svc, err := invokeFactory(ctn, r.factory)
+		svc, err := invokeFactory(ctn, "service", r.factory)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("service DI failed: %w", err)
 		}
 		r.register(grpcSrv, svc.Interface())
 	}
 
+	// readiness checks; the Temporal check is added once its client exists
+	hc := newHealth(nil)
+	if _, ok := ctn.groups[healthCheckerType]; ok {
+		checkers, err := ctn.resolve(reflect.SliceOf(healthCheckerType))
+		if err != nil {
+			return nil, fmt.Errorf("health check DI failed: %w", err)
+		}
+		hc.checkers = checkers.Interface().([]HealthChecker)
+	}
+	if _, ok := grpcSrv.GetServiceInfo()[healthpb.Health_ServiceDesc.ServiceName]; !ok {
+		healthpb.RegisterHealthServer(grpcSrv, &healthService{h: hc, srv: grpcSrv})
+	}
+
 	// HTTP multiplexer
 	mux := http.NewServeMux()
 
-	webProxy := GetWebProxy(grpcSrv)
-	mux.Handle("/api", b.cors.Handler(webProxy))
-
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", metrics)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
+	mux.HandleFunc("/livez", hc.livez)
+	mux.HandleFunc("/readyz", hc.readyz)
+
+	// filled in once every factory has been resolved
+	graph := &Graph{}
+	if b.debugGraph {
+		mux.Handle("/debug/di", graph)
+	}
 
 	// register REST controllers via DI
 	for _, factory := range b.restControllerRegs {
-		ctrlVal, err := invokeFactory(ctn, factory)
+		ctrlVal, err := invokeFactory(ctn, "controller", factory)
 		if err != nil {
 			return nil, fmt.Errorf("REST controller DI failed: %w", err)
 		}
 		ctrl := ctrlVal.Interface().(RestController)
+		var ctrlMiddleware []Middleware
+		if mc, ok := ctrl.(MiddlewareController); ok {
+			ctrlMiddleware = mc.Middleware()
+		}
 		for _, route := range ctrl.Routes() {
-			handler := methodFilterHandler(route.Method, route.Handler)
-			mux.Handle(route.Pattern, b.cors.Handler(handler))
+			if !route.RateLimit.isZero() {
+				if err := route.RateLimit.validate(); err != nil {
+					return nil, fmt.Errorf("invalid rate limit for route %s: %w", route.Pattern, err)
+				}
+				// the limiter only sees claims verified by Require; a token
+				// checked by route middleware is verified after it runs
+				if route.RateLimit.Key != RateLimitByIP && route.Require.isZero() {
+					return nil, fmt.Errorf("rate limit for route %s is keyed by tenant or user and needs Route.Require", route.Pattern)
+				}
+			}
+			routeMiddleware := append(append([]Middleware{}, ctrlMiddleware...), route.Middleware...)
+			for _, mw := range routeMiddleware {
+				if mw == nil {
+					return nil, fmt.Errorf("nil middleware for route %s", route.Pattern)
+				}
+			}
+			handler := chain(methodFilterHandler(route.Method, route.Handler), routeMiddleware)
+			h := recoveryMiddleware(rm, route.Pattern, chain(maxBodyMiddleware(b.maxBodyBytes, b.authz.middleware(route, b.rateLimits.middleware(route, scopeMiddleware(ctn, handler)))), b.middleware))
+			mux.Handle(route.Pattern, b.cors.Handler(requestIDMiddleware(traceMiddleware(b.tracerProvider, route.Pattern, rm.middleware(route.Pattern, h)))))
 			logger.Info("Registered REST route", zap.String("method", route.Method), zap.String("pattern", route.Pattern))
 		}
 	}
 
+	if b.transcoding {
+		routes, err := transcodedRoutes(grpcSrv, protoregistry.GlobalFiles)
+		if err != nil {
+			return nil, fmt.Errorf("HTTP transcoding failed: %w", err)
+		}
+		for _, route := range routes {
+			h := recoveryMiddleware(rm, route.path, chain(maxBodyMiddleware(b.maxBodyBytes, route.handler(grpcSrv)), b.middleware))
+			h = b.cors.Handler(requestIDMiddleware(traceMiddleware(b.tracerProvider, route.path, rm.middleware(route.path, h))))
+			if err := handleRoute(mux, route.pattern, h); err != nil {
+				return nil, fmt.Errorf("HTTP transcoding failed: %w", err)
+			}
+			logger.Info("Registered transcoded route", zap.String("pattern", route.pattern))
+		}
+	}
+
+	// gRPC-Web and Connect proxy, registered last so a conflicting route
+	// fails Build
+	proxy := GetWebProxy(grpcSrv)
+	proxy.checkOrigin = webSocketOriginChecker(b.cors)
+	proxy.sseHeartbeat = b.sseHeartbeat
+	proxy.sseQueryToken = b.sseQueryToken
+	webProxy := http.StripPrefix(b.webProxyPrefix, proxy)
+	for _, pattern := range webProxyPatterns(grpcSrv, b.webProxyPrefix) {
+		h := recoveryMiddleware(rm, pattern, chain(maxBodyMiddleware(b.maxBodyBytes, webProxy), b.middleware))
+		h = requestIDMiddleware(traceMiddleware(b.tracerProvider, pattern, rm.middleware(pattern, h)))
+		if b.sseQueryToken {
+			h = sseQueryTokenMiddleware(h)
+		}
+		if err := handleRoute(mux, pattern, b.cors.Handler(h)); err != nil {
+			return nil, fmt.Errorf("web proxy failed: %w", err)
+		}
+	}
+	servicesPath := b.webProxyPrefix + "/services"
+	services := recoveryMiddleware(rm, servicesPath, chain(servicesHandler(grpcSrv, b.webProxyPrefix), b.middleware))
+	services = b.cors.Handler(requestIDMiddleware(traceMiddleware(b.tracerProvider, servicesPath, rm.middleware(servicesPath, services))))
+	if err := handleRoute(mux, servicesPath, services); err != nil {
+		return nil, fmt.Errorf("web proxy failed: %w", err)
+	}
+	logger.Info("Registered web proxy", zap.String("prefix", b.webProxyPrefix))
+
 	// Add static file serving if configured
 	if b.staticDir != "" {
 		fileServer := http.FileServer(http.Dir(b.staticDir))
-		mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
+		mux.Handle("/static/", requestIDMiddleware(traceMiddleware(b.tracerProvider, "/static/", rm.middleware("/static/", chain(http.StripPrefix("/static/", fileServer), b.middleware)))))
 	}
 
-	// HTTP server with optimized timeouts
-	var readTimeout, writeTimeout, idleTimeout time.Duration
-	readTimeout = 5 * time.Minute
-	writeTimeout = 5 * time.Minute
-	idleTimeout = 10 * time.Minute
-
 	httpSrv := &http.Server{
-		Handler:      mux,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
-		IdleTimeout:  idleTimeout,
+		Handler:           mux,
+		ReadTimeout:       b.readTimeout,
+		ReadHeaderTimeout: b.readHeaderTimeout,
+		WriteTimeout:      b.writeTimeout,
+		IdleTimeout:       b.idleTimeout,
+		MaxHeaderBytes:    b.maxHeaderBytes,
 	}
 
 	if b.sslProvider != nil {
@@ -287,11 +846,16 @@ func (b *Builder) Build() (*BootServer, error) {
 
 	// Create a temporal worker if configured
 	var tw worker.Worker
-	var tc client.Client
 	var tcErr error
 	if b.temporalClientOpts != nil {
+		opts := *b.temporalClientOpts
+		opts.ContextPropagators = append(append([]workflow.ContextPropagator{}, opts.ContextPropagators...), RequestIDPropagator())
+		if b.tracerProvider != nil {
+			// client interceptors also apply to workers created from the client
+			opts.Interceptors = append([]interceptor.ClientInterceptor{newTemporalTracingInterceptor(b.tracerProvider, tracePropagator)}, opts.Interceptors...)
+		}
 		err := RetryWithExponentialBackoff(context.Background(), 5, 10*time.Second, func() error {
-			tc, tcErr = client.Dial(*b.temporalClientOpts)
+			tc, tcErr = client.Dial(opts)
 			if tcErr != nil {
 				return tcErr
 			}
@@ -302,9 +866,10 @@ func (b *Builder) Build() (*BootServer, error) {
 			return nil, fmt.Errorf("failed to create temporal client: %w", err)
 		}
 		tw = worker.New(tc, b.taskQueue, worker.Options{})
+		hc.checkers = append(hc.checkers, TemporalHealthCheck(tc))
 
 		for _, f := range b.activityRegs {
-			receiver, err := invokeFactory(ctn, f)
+			receiver, err := invokeFactory(ctn, "activity", f)
 			if err != nil {
 				return nil, fmt.Errorf("activity DI failed: %w", err)
 			}
@@ -316,6 +881,9 @@ func (b *Builder) Build() (*BootServer, error) {
 		}
 	}
 
+	*graph = *ctn.snapshot()
+	ctn.lifecycle = newLifecycle(ctn.order, b.lifecycleTimeout)
+
 	return &BootServer{
 		grpc:           grpcSrv,
 		http:           httpSrv,
@@ -324,11 +892,21 @@ func (b *Builder) Build() (*BootServer, error) {
 		sslProvider:    b.sslProvider,
 		temporalWorker: tw,
 		temporalClient: tc,
+		lifecycle:      ctn.lifecycle,
+		graph:          graph,
+		health:         hc,
+
+		shutdownTimeout:  b.shutdownTimeout,
+		grpcDrainTimeout: b.grpcDrainTimeout,
 	}, nil
 }
 
 // invokeFactory resolves arguments via container and calls the func.
-func invokeFactory(ctn *container, fn reflect.Value) (reflect.Value, error) {
+// The result is recorded so that it takes part in lifecycle hooks; kind
+// labels the factory in the dependency graph.
+func invokeFactory(ctn *container, kind string, fn reflect.Value) (reflect.Value, error) {
+	defer ctn.enter(factoryKey{kind: kind, fn: fn})()
+
 	args := make([]reflect.Value, fn.Type().NumIn())
 	for i := range args {
 		v, err := ctn.resolve(fn.Type().In(i))
@@ -337,5 +915,7 @@ func invokeFactory(ctn *container, fn reflect.Value) (reflect.Value, error) {
 		}
 		args[i] = v
 	}
-	return fn.Call(args)[0], nil
+	out := fn.Call(args)[0]
+	ctn.record(nil, out)
+	return out, nil
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/cors"
 	"github.com/stretchr/testify/assert"
@@ -44,18 +46,17 @@ func (p *depProvider) provide() *dep { p.called++; return &dep{id: 7} }
 /* ───────────────────────────────  TESTS  ─────────────────────────────────── */
 
 func TestBuilder_BuildValidation(t *testing.T) {
-	_, err := New().
-		GRPCPort(":0").
-		Build() // missing HTTPPort
+	_, err := New().Build() // nothing to serve
 	if err == nil {
-		t.Fatalf("Build() succeeded with missing HTTPPort; want error")
+		t.Fatalf("Build() succeeded without ports or temporal; want error")
 	}
 
 	_, err = New().
-		HTTPPort(":0").
-		Build() // missing GRPCPort
+		GRPCPort(":0").
+		EnableSSL(DirCache(t.TempDir())).
+		Build() // SSL without HTTPPort
 	if err == nil {
-		t.Fatalf("Build() succeeded with missing GRPCPort; want error")
+		t.Fatalf("Build() succeeded with SSL and no HTTPPort; want error")
 	}
 }
 
@@ -81,8 +82,8 @@ func TestBuilder_RegisterValidation(t *testing.T) {
 			return &testRestController{}
 		})
 
-	assert.Equal(t, len(builder.unary), 4)              // 3 default + 1 custom
-	assert.Equal(t, len(builder.stream), 4)             // 3 default + 1 custom
+	assert.Equal(t, len(builder.unary), 6)              // 5 default + 1 custom
+	assert.Equal(t, len(builder.stream), 6)             // 5 default + 1 custom
 	assert.Equal(t, len(builder.restControllerRegs), 1) // 1 REST controller
 	assert.NotNil(t, builder.cors)
 }
@@ -215,6 +216,148 @@ func TestBuilder_ProvideAs_BindsInterface(t *testing.T) {
 	}
 }
 
+type analyticsTag struct{}
+
+func (analyticsTag) Name() string { return "analytics" }
+
+func TestBuilder_ProvideNamedAs_InjectsQualifiedValue(t *testing.T) {
+	primary := &ifaceImpl{id: 1}
+	analytics := &ifaceImpl{id: 2}
+	spy := &regSpy{}
+
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideAs(primary, (*iface)(nil)).
+		ProvideNamedAs("analytics", analytics, (*iface)(nil)).
+		RegisterService(spy.fn, func(p iface, a Named[iface, analyticsTag]) *svc {
+			return &svc{d: &dep{id: p.GetID()*10 + a.Value.GetID()}}
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	assert.Equal(t, 12, spy.gotSrv.(*svc).d.id)
+}
+
+func TestBuilder_ProvideFuncNamed_Memoised(t *testing.T) {
+	p := &depProvider{}
+	spy1, spy2 := &regSpy{}, &regSpy{}
+
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFuncNamed("analytics", p.provide).
+		RegisterService(spy1.fn, func(d Named[*dep, analyticsTag]) *svc { return &svc{d: d.Value} }).
+		RegisterService(spy2.fn, func(d Named[*dep, analyticsTag]) *svc { return &svc{d: d.Value} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	assert.Equal(t, 1, p.called)
+	assert.Same(t, spy1.gotSrv.(*svc).d, spy2.gotSrv.(*svc).d)
+}
+
+func TestBuilder_ProvideNamed_EmptyName_CallsFatal(t *testing.T) {
+	mockLogger := withMockLogger(func() {
+		New().ProvideNamed("", &dep{})
+	})
+
+	assert.True(t, mockLogger.isFatalCalled, "expected logger.Fatal to be called")
+}
+
+func TestBuilder_ProvideFunc_ErrorAbortsBuildAndRunsCleanups(t *testing.T) {
+	cleaned := false
+
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFunc(func() (*dep, func(), error) {
+			return &dep{id: 1}, func() { cleaned = true }, nil
+		}).
+		ProvideFunc(func(d *dep) (iface, error) {
+			return nil, errors.New("dial tcp: connection refused")
+		}).
+		RegisterService((&regSpy{}).fn, func(d *dep, i iface) *svc { return &svc{d: d} }).
+		Build()
+
+	assert.ErrorContains(t, err, "service DI failed")
+	assert.ErrorContains(t, err, "connection refused")
+	assert.True(t, cleaned, "cleanup of already built providers must run when Build fails")
+}
+
+func TestBuilder_ProvideFunc_CleanupRunsOnShutdown(t *testing.T) {
+	cleaned := make(chan struct{})
+
+	boot, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFunc(func() (*dep, func(), error) {
+			return &dep{id: 1}, func() { close(cleaned) }, nil
+		}).
+		RegisterService((&regSpy{}).fn, func(d *dep) *svc { return &svc{d: d} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go boot.Serve(ctx)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-cleaned:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("provider cleanup did not run on shutdown")
+	}
+}
+
+func TestBootServer_Shutdown_RunsCleanupsOnce(t *testing.T) {
+	cleanups := 0
+	build := func() *BootServer {
+		boot, err := New().
+			GRPCPort(":0").
+			HTTPPort(":0").
+			ProvideFunc(func() (*dep, func(), error) {
+				return &dep{id: 1}, func() { cleanups++ }, nil
+			}).
+			RegisterService((&regSpy{}).fn, func(d *dep) *svc { return &svc{d: d} }).
+			Build()
+		if err != nil {
+			t.Fatalf("Build() failed: %v", err)
+		}
+		return boot
+	}
+
+	// built but never served
+	boot := build()
+	assert.NoError(t, boot.Shutdown(context.Background()))
+	assert.NoError(t, boot.Shutdown(context.Background()))
+	assert.Equal(t, 1, cleanups)
+
+	// Shutdown while Serve runs, then the context is cancelled
+	boot = build()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- boot.Serve(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, boot.Shutdown(context.Background()))
+	cancel()
+	<-done
+	assert.Equal(t, 2, cleanups)
+}
+
+func TestBuilder_ProvideFunc_UnsupportedSignature_CallsFatal(t *testing.T) {
+	mockLogger := withMockLogger(func() {
+		New().ProvideFunc(func() (*dep, int) { return nil, 0 })
+	})
+
+	assert.True(t, mockLogger.isFatalCalled, "expected logger.Fatal to be called")
+}
+
 // ------ Temporal worker tests (if applicable) ------
 
 func TestBuilder_WithTemporal_StoresConfig(t *testing.T) {
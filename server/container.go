@@ -1,39 +1,217 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 // Dependency injection container for Go API Boot.
 type container struct {
 	singletons map[reflect.Type]reflect.Value
 	providers  map[reflect.Type]reflect.Value // func(...) T
+	lifetimes  map[reflect.Type]Lifetime      // absent ⇒ Singleton
+
+	// contributions to []T, keyed by T (ProvideInto / ProvideFuncInto)
+	groups map[reflect.Type][]groupMember
+
+	// registrations qualified by name (ProvideNamed / ProvideFuncNamed)
+	named          map[namedKey]reflect.Value
+	namedProviders map[namedKey]reflect.Value
+
+	// resolved values in dependency order (dependencies before consumers)
+	order []reflect.Value
+	seen  map[any]struct{}
+
+	// keys of providers currently being invoked, guards against cycles
+	resolving map[any]bool
+
+	// serialises resolution once requests are being served
+	mu sync.Mutex
+
+	// set by Build; values first resolved while serving join it
+	lifecycle *lifecycle
+
+	// records the dependency graph during Build; nil afterwards
+	graph *graphRecorder
 }
 
 func newContainer(
 	singletons map[reflect.Type]reflect.Value,
 	providers map[reflect.Type]reflect.Value,
 ) *container {
-	return &container{singletons: singletons, providers: providers}
+	return &container{
+		singletons:     singletons,
+		providers:      providers,
+		lifetimes:      map[reflect.Type]Lifetime{},
+		groups:         map[reflect.Type][]groupMember{},
+		named:          map[namedKey]reflect.Value{},
+		namedProviders: map[namedKey]reflect.Value{},
+		seen:           map[any]struct{}{},
+		resolving:      map[any]bool{},
+		graph:          newGraphRecorder(),
+	}
 }
 
 func (c *container) resolve(t reflect.Type) (reflect.Value, error) {
+	if k, ok := asNamed(t); ok {
+		c.use(k)
+		v, err := c.resolveNamed(k)
+		if err != nil {
+			return v, err
+		}
+		return wrapNamed(t, v), nil
+	}
+
+	c.use(t)
 	if v, ok := c.singletons[t]; ok {
+		c.record(t, v)
 		return v, nil
 	}
 	if p, ok := c.providers[t]; ok {
-		args := make([]reflect.Value, p.Type().NumIn())
-		for i := range args {
-			v, err := c.resolve(p.Type().In(i))
+		switch c.lifetimes[t] {
+		case Scoped:
+			return reflect.Value{}, fmt.Errorf("request-scoped %v can only be resolved from a request context", t)
+		case Transient:
+			v, err := c.call(t, p)
 			if err != nil {
 				return v, err
 			}
-			args[i] = v
+			c.record(nil, v)
+			return v, nil
+		}
+
+		v, err := c.call(t, p)
+		if err != nil {
+			return v, err
 		}
-		v := p.Call(args)[0]
 		c.singletons[t] = v // memoise
+		c.record(t, v)
 		return v, nil
 	}
+	if t.Kind() == reflect.Slice {
+		if members, ok := c.groups[t.Elem()]; ok {
+			return c.resolveGroup(t, members)
+		}
+	}
 	return reflect.Value{}, fmt.Errorf("no provider for %v", t)
 }
+
+func (c *container) resolveNamed(k namedKey) (reflect.Value, error) {
+	if v, ok := c.named[k]; ok {
+		c.record(k, v)
+		return v, nil
+	}
+	if p, ok := c.namedProviders[k]; ok {
+		v, err := c.call(k, p)
+		if err != nil {
+			return v, err
+		}
+		c.named[k] = v // memoise
+		c.record(k, v)
+		return v, nil
+	}
+	return reflect.Value{}, fmt.Errorf("no provider for %v named %q", k.typ, k.name)
+}
+
+// call resolves the arguments of provider p, registered under key, and invokes it.
+func (c *container) call(key any, p reflect.Value) (reflect.Value, error) {
+	if c.resolving[key] {
+		return reflect.Value{}, fmt.Errorf("dependency cycle detected while resolving %v", key)
+	}
+	c.resolving[key] = true
+	defer delete(c.resolving, key)
+	defer c.enter(key)()
+
+	v, cleanup, err := callProvider(key, p, c.resolve)
+	if err != nil {
+		return v, err
+	}
+	if cleanup != nil {
+		// recorded before the value so that, in reverse order, the value's
+		// Stop hook runs before its cleanup
+		c.record(nil, reflect.ValueOf(cleanupHook(cleanup)))
+	}
+	return v, nil
+}
+
+// callProvider resolves the arguments of p with resolve and invokes it.
+// It handles all provider shapes:
+// func(...) T | func(...) (T, error) | func(...) (T, func(), error)
+func callProvider(
+	key any,
+	p reflect.Value,
+	resolve func(reflect.Type) (reflect.Value, error),
+) (reflect.Value, func(), error) {
+	args := make([]reflect.Value, p.Type().NumIn())
+	for i := range args {
+		v, err := resolve(p.Type().In(i))
+		if err != nil {
+			return v, nil, err
+		}
+		args[i] = v
+	}
+
+	out := p.Call(args)
+	if errV := out[len(out)-1]; len(out) > 1 && !errV.IsNil() {
+		return reflect.Value{}, nil, fmt.Errorf("provider of %v failed: %w", key, errV.Interface().(error))
+	}
+	if len(out) == 3 && !out[1].IsNil() {
+		return out[0], out[1].Interface().(func()), nil
+	}
+	return out[0], nil, nil
+}
+
+// runCleanups invokes the cleanup funcs of providers resolved so far in
+// reverse order. Used when Build fails after some providers already ran.
+func (c *container) runCleanups() {
+	for i := len(c.order) - 1; i >= 0; i-- {
+		if h, ok := c.order[i].Interface().(cleanupHook); ok {
+			h()
+		}
+	}
+}
+
+// cleanupHook adapts the cleanup func returned by a provider to Stopper.
+type cleanupHook func()
+
+func (h cleanupHook) Stop(context.Context) error {
+	h()
+	return nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isProviderFunc reports whether t is one of the supported provider signatures:
+// func(...) T, func(...) (T, error) or func(...) (T, func(), error).
+func isProviderFunc(t reflect.Type) bool {
+	switch t.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return t.Out(1) == errorType
+	case 3:
+		return t.Out(1) == reflect.TypeOf(func() {}) && t.Out(2) == errorType
+	}
+	return false
+}
+
+// record appends v to the resolution order the first time it is seen.
+// The same instance bound under several types is only recorded once;
+// non-comparable values are de-duplicated by the key they were resolved as.
+func (c *container) record(key any, v reflect.Value) {
+	if !v.IsValid() || !v.CanInterface() {
+		return
+	}
+	if v.Comparable() {
+		key = v.Interface()
+	}
+	if key != nil {
+		if _, ok := c.seen[key]; ok {
+			return
+		}
+		c.seen[key] = struct{}{}
+	}
+	c.order = append(c.order, v)
+}
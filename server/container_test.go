@@ -3,6 +3,7 @@ package server
 import (
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -105,3 +106,111 @@ func TestContainer_ResolveMissing(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// ──────────────────────────────────────────────────────────────────────────────
+// 5. named registrations via Named[T, Tag]
+// ──────────────────────────────────────────────────────────────────────────────
+type primaryTag struct{}
+
+func (primaryTag) Name() string { return "primary" }
+
+type replicaTag struct{}
+
+func (replicaTag) Name() string { return "replica" }
+
+func TestContainer_ResolveNamed(t *testing.T) {
+	c := makeContainer()
+
+	unnamed := &A{id: 1}
+	primary := &A{id: 2}
+	c.singletons[reflect.TypeOf(unnamed)] = reflect.ValueOf(unnamed)
+	c.named[namedKey{name: "primary", typ: reflect.TypeOf(primary)}] = reflect.ValueOf(primary)
+	c.namedProviders[namedKey{name: "replica", typ: reflect.TypeOf(&A{})}] = reflect.ValueOf(func(p Named[*A, primaryTag]) *A {
+		return &A{id: p.Value.id + 10}
+	})
+
+	v, err := c.resolve(reflect.TypeOf(Named[*A, primaryTag]{}))
+	if err != nil {
+		t.Fatalf("resolve error: %v", err)
+	}
+	if got := v.Interface().(Named[*A, primaryTag]).Value; got != primary {
+		t.Fatalf("named resolve returned %+v, want primary", got)
+	}
+
+	v, err = c.resolve(reflect.TypeOf(Named[*A, replicaTag]{}))
+	if err != nil {
+		t.Fatalf("resolve error: %v", err)
+	}
+	if got := v.Interface().(Named[*A, replicaTag]).Value; got.id != 12 {
+		t.Fatalf("named provider returned %+v, want id 12", got)
+	}
+
+	v, _ = c.resolve(reflect.TypeOf(unnamed))
+	if v.Interface().(*A) != unnamed {
+		t.Fatalf("unnamed registration must not be shadowed by named ones")
+	}
+}
+
+func TestContainer_ResolveNamedMissing(t *testing.T) {
+	c := makeContainer()
+	c.singletons[reflect.TypeOf(&A{})] = reflect.ValueOf(&A{})
+
+	_, err := c.resolve(reflect.TypeOf(Named[*A, primaryTag]{}))
+	if err == nil {
+		t.Fatalf("expected error for missing named provider, got nil")
+	}
+}
+
+// ──────────────────────────────────────────────────────────────────────────────
+// 6. provider cycle must error instead of recursing forever
+// ──────────────────────────────────────────────────────────────────────────────
+func TestContainer_ResolveCycle(t *testing.T) {
+	c := makeContainer()
+
+	c.providers[reflect.TypeOf(&cycA{})] = reflect.ValueOf(func(*cycB) *cycA { return &cycA{} })
+	c.providers[reflect.TypeOf(&cycB{})] = reflect.ValueOf(func(*cycA) *cycB { return &cycB{} })
+
+	_, err := c.resolve(reflect.TypeOf(&cycA{}))
+	if err == nil {
+		t.Fatalf("expected cycle error, got nil")
+	}
+}
+
+// ──────────────────────────────────────────────────────────────────────────────
+// 7. error- and cleanup-returning providers
+// ──────────────────────────────────────────────────────────────────────────────
+func TestContainer_ProviderError(t *testing.T) {
+	c := makeContainer()
+	c.providers[reflect.TypeOf(&A{})] = reflect.ValueOf(func() (*A, error) {
+		return nil, errors.New("connect refused")
+	})
+
+	_, err := c.resolve(reflect.TypeOf(&A{}))
+	if err == nil || !strings.Contains(err.Error(), "connect refused") {
+		t.Fatalf("expected provider error to propagate, got %v", err)
+	}
+}
+
+func TestContainer_ProviderCleanup(t *testing.T) {
+	c := makeContainer()
+	var cleaned []string
+	c.providers[reflect.TypeOf(&A{})] = reflect.ValueOf(func() (*A, func(), error) {
+		return &A{id: 1}, func() { cleaned = append(cleaned, "A") }, nil
+	})
+	c.providers[reflect.TypeOf(&B{})] = reflect.ValueOf(func(a *A) (*B, func(), error) {
+		return &B{dep: a}, func() { cleaned = append(cleaned, "B") }, nil
+	})
+
+	v, err := c.resolve(reflect.TypeOf(&B{}))
+	if err != nil {
+		t.Fatalf("resolve error: %v", err)
+	}
+	if v.Interface().(*B).dep.id != 1 {
+		t.Fatalf("nested dependency not injected")
+	}
+
+	c.runCleanups()
+	if strings.Join(cleaned, ",") != "B,A" {
+		t.Fatalf("cleanups must run in reverse order, got %v", cleaned)
+	}
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GraphNode is one entry of the resolved dependency graph: a registered type,
+// a group member or a service / REST controller / activity factory.
+type GraphNode struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"` // qualifier of named registrations
+	Kind     string `json:"kind"`           // value | provider | group | context | service | controller | activity
+	Provider string `json:"provider,omitempty"`
+	Lifetime string `json:"lifetime"`
+
+	Dependencies []string `json:"dependencies,omitempty"`
+	Consumers    []string `json:"consumers,omitempty"`
+
+	// time spent in the provider or factory, including resolving its
+	// dependencies; summed over calls for transients
+	ResolveTime time.Duration `json:"resolveTimeNs"`
+}
+
+// Graph is the dependency graph recorded while Build resolved the container.
+// Nodes are listed in the order they were first requested. Request-scoped
+// providers are listed with their declared dependencies.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+}
+
+// JSON returns the graph as indented JSON.
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT returns the graph in Graphviz DOT format; edges point from consumer to
+// dependency.
+//
+// Example:
+//
+//	os.WriteFile("di.dot", []byte(boot.Graph().DOT()), 0o644)
+//	// dot -Tsvg di.dot > di.svg
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph di {\n  rankdir=LR;\n  node [shape=box, fontname=\"monospace\"];\n")
+	for _, n := range g.Nodes {
+		label := n.ID
+		if n.Provider != "" {
+			label += "\n" + n.Provider
+		}
+		label += fmt.Sprintf("\n%s %s %s", n.Kind, n.Lifetime, n.ResolveTime)
+		fmt.Fprintf(&sb, "  %q [label=%q];\n", n.ID, label)
+	}
+	for _, n := range g.Nodes {
+		for _, d := range n.Dependencies {
+			fmt.Fprintf(&sb, "  %q -> %q;\n", n.ID, d)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// ServeHTTP serves the graph as JSON, or as DOT with ?format=dot.
+func (g *Graph) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		_, _ = w.Write([]byte(g.DOT()))
+		return
+	}
+	body, err := g.JSON()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// graphRecorder tracks which node requested which while the container
+// resolves. It is only active during Build.
+type graphRecorder struct {
+	nodes map[string]*GraphNode
+	order []string
+	stack []string // nodes whose provider is currently running
+}
+
+func newGraphRecorder() *graphRecorder {
+	return &graphRecorder{nodes: map[string]*GraphNode{}}
+}
+
+// graphID renders a container key the same way validation errors do.
+func graphID(key any) string {
+	switch k := key.(type) {
+	case reflect.Type:
+		return k.String()
+	case namedKey:
+		return fmt.Sprintf("%v named %q", k.typ, k.name)
+	default:
+		return fmt.Sprint(key)
+	}
+}
+
+func funcName(fn reflect.Value) string {
+	if f := runtime.FuncForPC(fn.Pointer()); f != nil {
+		return f.Name()
+	}
+	return fn.Type().String()
+}
+
+// use records that key is requested by the node on top of the stack.
+func (c *container) use(key any) {
+	if c.graph == nil {
+		return
+	}
+	n := c.node(key)
+	if len(c.graph.stack) == 0 {
+		return
+	}
+	parent := c.graph.nodes[c.graph.stack[len(c.graph.stack)-1]]
+	if !slices.Contains(parent.Dependencies, n.ID) {
+		parent.Dependencies = append(parent.Dependencies, n.ID)
+	}
+	if !slices.Contains(n.Consumers, parent.ID) {
+		n.Consumers = append(n.Consumers, parent.ID)
+	}
+}
+
+// enter marks key's provider as running; the returned func records its duration.
+func (c *container) enter(key any) func() {
+	if c.graph == nil {
+		return func() {}
+	}
+	n := c.node(key)
+	c.graph.stack = append(c.graph.stack, n.ID)
+	start := time.Now()
+	return func() {
+		n.ResolveTime += time.Since(start)
+		c.graph.stack = c.graph.stack[:len(c.graph.stack)-1]
+	}
+}
+
+// node returns the node for key, describing it from the registrations on
+// first use.
+func (c *container) node(key any) *GraphNode {
+	id := graphID(key)
+	if n, ok := c.graph.nodes[id]; ok {
+		return n
+	}
+
+	n := &GraphNode{ID: id, Lifetime: Singleton.String()}
+	switch k := key.(type) {
+	case reflect.Type:
+		n.Type = k.String()
+		if _, ok := c.singletons[k]; ok {
+			n.Kind = "value"
+		} else if p, ok := c.providers[k]; ok {
+			n.Kind, n.Provider, n.Lifetime = "provider", funcName(p), c.lifetimes[k].String()
+		} else if k.Kind() == reflect.Slice && c.groups[k.Elem()] != nil {
+			n.Kind = "group"
+		} else if k == contextType {
+			n.Kind, n.Lifetime = "context", Scoped.String()
+		}
+	case namedKey:
+		n.Type, n.Name = k.typ.String(), k.name
+		if p, ok := c.namedProviders[k]; ok {
+			n.Kind, n.Provider = "provider", funcName(p)
+		} else {
+			n.Kind = "value"
+		}
+	case groupKey:
+		n.Type, n.Kind = k.elem.String(), "provider"
+		n.Provider = funcName(c.groups[k.elem][k.index].provider)
+	case factoryKey:
+		n.Type, n.Kind, n.Provider = k.fn.Type().Out(0).String(), k.kind, funcName(k.fn)
+	}
+
+	c.graph.nodes[id] = n
+	c.graph.order = append(c.graph.order, id)
+	return n
+}
+
+// factoryKey identifies a service / REST controller / activity factory.
+type factoryKey struct {
+	kind string
+	fn   reflect.Value
+}
+
+func (k factoryKey) String() string {
+	return k.kind + " " + k.fn.Type().Out(0).String()
+}
+
+// snapshot stops recording and returns the graph. Request-scoped providers are
+// never resolved during Build, so they are added with their declared
+// dependencies.
+func (c *container) snapshot() *Graph {
+	if c.graph == nil {
+		return &Graph{}
+	}
+
+	var scoped []reflect.Type
+	for t, l := range c.lifetimes {
+		if l == Scoped {
+			scoped = append(scoped, t)
+		}
+	}
+	sort.Slice(scoped, func(i, j int) bool { return scoped[i].String() < scoped[j].String() })
+	for _, t := range scoped {
+		p := c.providers[t]
+		done := c.enter(t)
+		for i := 0; i < p.Type().NumIn(); i++ {
+			in := p.Type().In(i)
+			if k, ok := asNamed(in); ok {
+				c.use(k)
+			} else {
+				c.use(in)
+			}
+		}
+		done()
+		c.graph.nodes[graphID(t)].ResolveTime = 0
+	}
+
+	g := &Graph{Nodes: make([]GraphNode, 0, len(c.graph.order))}
+	for _, id := range c.graph.order {
+		g.Nodes = append(g.Nodes, *c.graph.nodes[id])
+	}
+	c.graph = nil
+	return g
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func graphNode(t *testing.T, g *Graph, id string) GraphNode {
+	t.Helper()
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	t.Fatalf("node %q not found in graph", id)
+	return GraphNode{}
+}
+
+func buildGraph(t *testing.T, b *Builder) *Graph {
+	t.Helper()
+	boot, err := b.GRPCPort(":0").HTTPPort(":0").Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	t.Cleanup(func() { boot.lnGrpc.Close(); boot.lnHTTP.Close() })
+	return boot.Graph()
+}
+
+func TestBootServer_Graph_RecordsProvidersAndConsumers(t *testing.T) {
+	p := &depProvider{}
+	g := buildGraph(t, New().
+		Provide(&A{id: 1}).
+		ProvideFunc(p.provide).
+		ProvideFunc(func(a *A, d *dep) *B { return &B{dep: a} }).
+		ProvideNamed("analytics", &C{}).
+		RegisterService((&regSpy{}).fn, func(b *B, d *dep) *svc { return &svc{d: d} }).
+		RegisterService((&regSpy{}).fn, func(c Named[*C, analyticsTag]) *ifaceImpl { return &ifaceImpl{} }))
+
+	svcNode := graphNode(t, g, "service *server.svc")
+	assert.Equal(t, "service", svcNode.Kind)
+	assert.Equal(t, []string{"*server.B", "*server.dep"}, svcNode.Dependencies)
+
+	bNode := graphNode(t, g, "*server.B")
+	assert.Equal(t, "provider", bNode.Kind)
+	assert.Equal(t, "singleton", bNode.Lifetime)
+	assert.Contains(t, bNode.Provider, "TestBootServer_Graph_RecordsProvidersAndConsumers")
+	assert.Equal(t, []string{"*server.A", "*server.dep"}, bNode.Dependencies)
+	assert.Equal(t, []string{"service *server.svc"}, bNode.Consumers)
+	assert.Positive(t, bNode.ResolveTime)
+
+	depNode := graphNode(t, g, "*server.dep")
+	assert.Equal(t, []string{"*server.B", "service *server.svc"}, depNode.Consumers)
+	assert.Contains(t, depNode.Provider, "depProvider).provide")
+
+	assert.Equal(t, "value", graphNode(t, g, "*server.A").Kind)
+
+	named := graphNode(t, g, `*server.C named "analytics"`)
+	assert.Equal(t, "analytics", named.Name)
+	assert.Equal(t, []string{"service *server.ifaceImpl"}, named.Consumers)
+}
+
+func TestBootServer_Graph_GroupsAndScoped(t *testing.T) {
+	g := buildGraph(t, New().
+		ProvideFunc((&depProvider{}).provide).
+		ProvideFuncInto(func(d *dep) *namedSink { return &namedSink{name: "a"} }, (*sink)(nil)).
+		ProvideScoped(func(ctx context.Context, d *dep) *unitOfWork { return &unitOfWork{dep: d} }).
+		RegisterService((&regSpy{}).fn, func(sinks []sink) *sinkConsumer { return &sinkConsumer{sinks: sinks} }))
+
+	group := graphNode(t, g, "[]server.sink")
+	assert.Equal(t, "group", group.Kind)
+	assert.Equal(t, []string{"[]server.sink member #0"}, group.Dependencies)
+	assert.Equal(t, []string{"*server.dep"}, graphNode(t, g, "[]server.sink member #0").Dependencies)
+
+	scoped := graphNode(t, g, "*server.unitOfWork")
+	assert.Equal(t, "scoped", scoped.Lifetime)
+	assert.Equal(t, []string{"context.Context", "*server.dep"}, scoped.Dependencies)
+	assert.Zero(t, scoped.ResolveTime)
+}
+
+func TestGraph_DOTAndHTTP(t *testing.T) {
+	g := &Graph{Nodes: []GraphNode{
+		{ID: "service *x.Svc", Kind: "service", Lifetime: "singleton", Dependencies: []string{"*x.Repo"}},
+		{ID: "*x.Repo", Kind: "value", Lifetime: "singleton", Consumers: []string{"service *x.Svc"}},
+	}}
+
+	dot := g.DOT()
+	assert.Contains(t, dot, "digraph di {")
+	assert.Contains(t, dot, `"service *x.Svc" -> "*x.Repo";`)
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/di", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var got Graph
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, *g, got)
+
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/di?format=dot", nil))
+	assert.Equal(t, dot, rec.Body.String())
+}
+
+func TestBuilder_DebugGraph_ServesEndpoint(t *testing.T) {
+	boot, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		DebugGraph().
+		Provide(&dep{}).
+		RegisterService((&regSpy{}).fn, func(d *dep) *svc { return &svc{d: d} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer boot.lnGrpc.Close()
+	defer boot.lnHTTP.Close()
+
+	rec := httptest.NewRecorder()
+	boot.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/di", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var got Graph
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, boot.Graph().Nodes, got.Nodes)
+}
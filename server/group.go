@@ -0,0 +1,46 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// groupMember is one contribution to a group: either a ready value or a
+// provider func resolved lazily like ProvideFunc.
+type groupMember struct {
+	value    reflect.Value
+	provider reflect.Value
+}
+
+// resolveGroup builds the []T slice of type t from every contribution to T.
+func (c *container) resolveGroup(t reflect.Type, members []groupMember) (reflect.Value, error) {
+	defer c.enter(t)()
+
+	out := reflect.MakeSlice(t, 0, len(members))
+	for i, m := range members {
+		v := m.value
+		if m.provider.IsValid() {
+			key := groupKey{elem: t.Elem(), index: i}
+			c.use(key)
+			var err error
+			v, err = c.call(key, m.provider)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			c.record(nil, v)
+		}
+		out = reflect.Append(out, v)
+	}
+	c.singletons[t] = out // memoise
+	return out, nil
+}
+
+// groupKey identifies a group member provider in error messages and cycle checks.
+type groupKey struct {
+	elem  reflect.Type
+	index int
+}
+
+func (k groupKey) String() string {
+	return fmt.Sprintf("[]%v member #%d", k.elem, k.index)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sink is the group element type used in these tests.
+type sink interface{ Name() string }
+
+type namedSink struct{ name string }
+
+func (s *namedSink) Name() string { return s.name }
+
+// sinkConsumer aggregates every contributed sink.
+type sinkConsumer struct{ sinks []sink }
+
+func sinkNames(sinks []sink) []string {
+	names := make([]string, len(sinks))
+	for i, s := range sinks {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+func TestBuilder_ProvideInto_InjectsSlice(t *testing.T) {
+	spy := &regSpy{}
+	p := &depProvider{}
+
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFunc(p.provide).
+		ProvideInto(&namedSink{name: "audit"}, (*sink)(nil)).
+		ProvideFuncInto(func(d *dep) *namedSink { return &namedSink{name: "metrics"} }, (*sink)(nil)).
+		ProvideInto(&namedSink{name: "log"}, (*sink)(nil)).
+		RegisterService(spy.fn, func(sinks []sink) *sinkConsumer { return &sinkConsumer{sinks: sinks} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	got := spy.gotSrv.(*sinkConsumer)
+	assert.Equal(t, []string{"audit", "metrics", "log"}, sinkNames(got.sinks))
+	assert.Equal(t, 1, p.called)
+}
+
+func TestBuilder_ProvideInto_GroupIsShared(t *testing.T) {
+	spy1, spy2 := &regSpy{}, &regSpy{}
+	calls := 0
+
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFuncInto(func() *namedSink { calls++; return &namedSink{name: "a"} }, (*sink)(nil)).
+		RegisterService(spy1.fn, func(sinks []sink) *sinkConsumer { return &sinkConsumer{sinks: sinks} }).
+		RegisterService(spy2.fn, func(sinks []sink) *sinkConsumer { return &sinkConsumer{sinks: sinks} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Same(t, spy1.gotSrv.(*sinkConsumer).sinks[0], spy2.gotSrv.(*sinkConsumer).sinks[0])
+}
+
+func TestBuilder_ProvideFuncInto_ValidatesMemberDependencies(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFuncInto(func(d *dep) *namedSink { return &namedSink{} }, (*sink)(nil)).
+		RegisterService((&regSpy{}).fn, func(sinks []sink) *sinkConsumer { return &sinkConsumer{} }).
+		Build()
+
+	assert.ErrorContains(t, err, "no provider for *server.dep (requested by group member of []server.sink)")
+}
+
+func TestBuilder_ProvideInto_WrongType_CallsFatal(t *testing.T) {
+	mockLogger := withMockLogger(func() {
+		New().ProvideInto(&dep{}, (*sink)(nil))
+	})
+
+	assert.True(t, mockLogger.isFatalCalled, "expected logger.Fatal to be called")
+	assert.Equal(t, "Provided value cannot be added to the group", mockLogger.fatalMsg)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Transcoded and Connect calls are served by handing grpc.Server.ServeHTTP a
+// synthetic HTTP/2 gRPC request, so they pass through the same interceptors
+// as native gRPC calls.
+
+// unforwardedHeaders are request headers not passed on as metadata.
+var unforwardedHeaders = map[string]bool{
+	"Accept-Encoding": true, "Connection": true, "Content-Encoding": true, "Content-Length": true,
+	"Content-Type": true, "Keep-Alive": true, "Te": true, "Trailer": true, "Transfer-Encoding": true,
+	"Upgrade": true, http.CanonicalHeaderKey(grpcWebHeader): true,
+}
+
+// grpcFrame prefixes payload with the uncompressed gRPC message header.
+func grpcFrame(payload []byte) []byte {
+	frame := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	return frame
+}
+
+// newGRPCRequest turns r into a gRPC call to fullMethod reading framed
+// messages from body, which the gRPC server closes when the call ends if it
+// is an io.ReadCloser. HTTP headers (Authorization, X-Request-Id, traceparent,
+// ...) are forwarded as metadata; gRPC and binary headers are dropped.
+func newGRPCRequest(r *http.Request, fullMethod string, body io.Reader) *http.Request {
+	req := r.Clone(r.Context())
+	req.Method = http.MethodPost
+	req.URL = &url.URL{Path: fullMethod}
+	req.RequestURI = fullMethod
+	req.ProtoMajor, req.ProtoMinor = 2, 0
+	if rc, ok := body.(io.ReadCloser); ok {
+		req.Body = rc
+	} else {
+		req.Body = io.NopCloser(body)
+	}
+	req.ContentLength = -1
+	req.Header = http.Header{}
+	for k, vv := range r.Header {
+		if unforwardedHeaders[k] || strings.HasPrefix(k, "Grpc-") || strings.HasSuffix(k, "-Bin") {
+			continue
+		}
+		req.Header[k] = vv
+	}
+	req.Header.Set("Content-Type", grpcContentType)
+	req.Header.Set("Te", "trailers")
+	return req
+}
+
+// grpcStatus reads the status the gRPC server wrote into h. body is reported
+// when the server rejected the request before running the method.
+func grpcStatus(h http.Header, body []byte) *status.Status {
+	if raw := h.Get("Grpc-Status-Details-Bin"); raw != "" {
+		if b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(raw, "=")); err == nil {
+			var p spb.Status
+			if proto.Unmarshal(b, &p) == nil {
+				return status.FromProto(&p)
+			}
+		}
+	}
+	code, err := strconv.Atoi(h.Get("Grpc-Status"))
+	if err != nil {
+		return status.New(codes.Internal, strings.TrimSpace(string(body)))
+	}
+	msg, err := url.PathUnescape(h.Get("Grpc-Message"))
+	if err != nil {
+		msg = h.Get("Grpc-Message")
+	}
+	return status.New(codes.Code(code), msg)
+}
+
+// copyMetadataHeaders copies the header metadata set by the method, e.g.
+// retry-after, from the gRPC response headers in src to dst.
+func copyMetadataHeaders(dst, src http.Header) {
+	for k, vv := range src {
+		k = http.CanonicalHeaderKey(k) // metadata keys are lower case
+		if k == "Content-Type" || k == "Trailer" || strings.HasPrefix(k, "Grpc-") ||
+			strings.HasPrefix(k, http2.TrailerPrefix) || strings.HasSuffix(k, "-Bin") {
+			continue
+		}
+		dst[k] = vv
+	}
+}
+
+// trailerMetadata returns the trailer metadata set by the method.
+func trailerMetadata(src http.Header) map[string][]string {
+	md := map[string][]string{}
+	for k, vv := range src {
+		if name, ok := strings.CutPrefix(k, http2.TrailerPrefix); ok && !strings.HasSuffix(strings.ToLower(name), "-bin") {
+			md[strings.ToLower(name)] = vv
+		}
+	}
+	return md
+}
+
+// grpcResponseBuffer buffers a unary gRPC response; headers and trailers
+// share one map.
+type grpcResponseBuffer struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func newGRPCResponseBuffer() *grpcResponseBuffer {
+	return &grpcResponseBuffer{header: http.Header{}}
+}
+
+func (w *grpcResponseBuffer) Header() http.Header         { return w.header }
+func (w *grpcResponseBuffer) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *grpcResponseBuffer) WriteHeader(int)             {}
+func (w *grpcResponseBuffer) Flush()                      {}
+
+func (w *grpcResponseBuffer) status() *status.Status { return grpcStatus(w.header, w.body.Bytes()) }
+
+func (w *grpcResponseBuffer) copyHeaders(dst http.Header) { copyMetadataHeaders(dst, w.header) }
+
+// payload returns the single response message, still encoded.
+func (w *grpcResponseBuffer) payload() ([]byte, error) {
+	b := w.body.Bytes()
+	if len(b) < 5 {
+		return nil, errors.New("empty gRPC response")
+	}
+	if b[0] != 0 {
+		return nil, errors.New("compressed gRPC response")
+	}
+	n := binary.BigEndian.Uint32(b[1:5])
+	if uint32(len(b)-5) < n {
+		return nil, errors.New("truncated gRPC response")
+	}
+	return b[5 : 5+n], nil
+}
+
+func (w *grpcResponseBuffer) message(m proto.Message) error {
+	b, err := w.payload()
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(b, m)
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/odm"
+	"go.temporal.io/sdk/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthChecker reports whether a dependency is ready to serve traffic.
+// Register checkers with AddHealthCheck / AddHealthCheckFunc; they back
+// /readyz and the grpc.health.v1 Health service.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// HealthCheck adapts a func to HealthChecker.
+//
+// Example:
+//
+//	builder.AddHealthCheck(server.HealthCheck("redis", func(ctx context.Context) error {
+//	    return rdb.Ping(ctx).Err()
+//	}))
+func HealthCheck(name string, check func(ctx context.Context) error) HealthChecker {
+	return &funcChecker{name: name, check: check}
+}
+
+type funcChecker struct {
+	name  string
+	check func(context.Context) error
+}
+
+func (c *funcChecker) Name() string                    { return c.name }
+func (c *funcChecker) Check(ctx context.Context) error { return c.check(ctx) }
+
+// TemporalHealthCheck checks the Temporal frontend. Build registers it
+// automatically when WithTemporal is configured.
+func TemporalHealthCheck(c client.Client) HealthChecker {
+	return HealthCheck("temporal", func(ctx context.Context) error {
+		_, err := c.CheckHealth(ctx, &client.CheckHealthRequest{})
+		return err
+	})
+}
+
+// MongoHealthCheck reports Mongo as unavailable when Ping fails.
+// MongoModule registers it.
+func MongoHealthCheck(c odm.MongoClient) HealthChecker {
+	return HealthCheck("mongo", func(ctx context.Context) error {
+		return c.Ping(ctx, nil)
+	})
+}
+
+const (
+	healthCheckTimeout  = 5 * time.Second
+	healthWatchInterval = 5 * time.Second
+)
+
+var healthCheckerType = reflect.TypeOf((*HealthChecker)(nil)).Elem()
+
+// CheckResult is the outcome of one HealthChecker.
+type CheckResult struct {
+	Status   string `json:"status"` // ok | error
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// HealthReport is the body of /livez and /readyz.
+type HealthReport struct {
+	Status string                 `json:"status"` // ok | unavailable | shutting_down
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// health runs the registered checkers.
+type health struct {
+	checkers []HealthChecker
+	timeout  time.Duration
+	draining atomic.Bool // set once shutdown starts
+}
+
+func newHealth(checkers []HealthChecker) *health {
+	return &health{checkers: checkers, timeout: healthCheckTimeout}
+}
+
+// check runs every checker concurrently, each bounded by h.timeout.
+func (h *health) check(ctx context.Context) HealthReport {
+	if h.draining.Load() {
+		return HealthReport{Status: "shutting_down"}
+	}
+
+	results := make([]CheckResult, len(h.checkers))
+	var wg sync.WaitGroup
+	for i, c := range h.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.Check(ctx)
+			results[i] = CheckResult{Status: "ok", Duration: time.Since(start).String()}
+			if err != nil {
+				results[i].Status, results[i].Error = "error", err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: "ok", Checks: make(map[string]CheckResult, len(results))}
+	for i, r := range results {
+		if r.Status != "ok" {
+			report.Status = "unavailable"
+		}
+		report.Checks[h.checkers[i].Name()] = r
+	}
+	return report
+}
+
+// livez reports that the process is up; it runs no checks.
+func (h *health) livez(w http.ResponseWriter, _ *http.Request) {
+	writeHealth(w, HealthReport{Status: "ok"})
+}
+
+// readyz runs every checker and answers 503 if any fails.
+func (h *health) readyz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, h.check(r.Context()))
+}
+
+func writeHealth(w http.ResponseWriter, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// healthService implements grpc.health.v1.Health on top of the same checkers.
+// Every registered service reports the overall status.
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+	h   *health
+	srv *grpc.Server
+}
+
+func (s *healthService) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.Service != "" {
+		if _, ok := s.srv.GetServiceInfo()[req.Service]; !ok {
+			return nil, status.Errorf(codes.NotFound, "unknown service %q", req.Service)
+		}
+	}
+	return &healthpb.HealthCheckResponse{Status: s.status(ctx)}, nil
+}
+
+// Watch sends the status immediately and then whenever it changes.
+func (s *healthService) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	if req.Service != "" {
+		if _, ok := s.srv.GetServiceInfo()[req.Service]; !ok {
+			last = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: last}); err != nil {
+				return err
+			}
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		}
+	}
+
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+	for {
+		if st := s.status(stream.Context()); st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// AuthFuncOverride lets probes call the health service without a token.
+func (s *healthService) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *healthService) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	if s.h.check(ctx).Status != "ok" {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
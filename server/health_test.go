@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/odm"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// toggleCheck fails while err is set.
+type toggleCheck struct {
+	name string
+	err  error
+}
+
+func (c *toggleCheck) Name() string                { return c.name }
+func (c *toggleCheck) Check(context.Context) error { return c.err }
+
+func getHealth(t *testing.T, h http.HandlerFunc) (int, HealthReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var report HealthReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, report
+}
+
+func TestHealth_Readyz_ReportsEachCheck(t *testing.T) {
+	db := &toggleCheck{name: "db"}
+	h := newHealth([]HealthChecker{db, HealthCheck("cache", func(context.Context) error { return nil })})
+
+	code, report := getHealth(t, h.readyz)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", report.Status)
+	assert.Equal(t, "ok", report.Checks["db"].Status)
+	assert.Equal(t, "ok", report.Checks["cache"].Status)
+
+	db.err = errors.New("connection refused")
+	code, report = getHealth(t, h.readyz)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", report.Status)
+	assert.Equal(t, CheckResult{Status: "error", Error: "connection refused", Duration: report.Checks["db"].Duration}, report.Checks["db"])
+	assert.Equal(t, "ok", report.Checks["cache"].Status)
+
+	// liveness does not depend on checks
+	code, report = getHealth(t, h.livez)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", report.Status)
+}
+
+func TestHealth_Readyz_TimesOutSlowChecks(t *testing.T) {
+	h := newHealth([]HealthChecker{HealthCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})})
+	h.timeout = 20 * time.Millisecond
+
+	code, report := getHealth(t, h.readyz)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestHealth_Readyz_FailsWhileDraining(t *testing.T) {
+	h := newHealth(nil)
+	h.draining.Store(true)
+
+	code, report := getHealth(t, h.readyz)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "shutting_down", report.Status)
+}
+
+func TestBuilder_HealthChecks_GRPCAndHTTP(t *testing.T) {
+	db := &toggleCheck{name: "db"}
+	bs, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		Provide(db).
+		AddHealthCheckFunc(func(c *toggleCheck) HealthChecker { return c }).
+		AddHealthCheck(HealthCheck("static", func(context.Context) error { return nil })).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bs.Serve(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, err := grpc.NewClient(bs.lnGrpc.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	// no token needed
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	db.err = errors.New("down")
+	resp, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: healthpb.Health_ServiceDesc.ServiceName})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "no.such.Service"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	httpResp, err := http.Get("http://" + bs.lnHTTP.Addr().String() + "/readyz")
+	if err != nil {
+		t.Fatalf("GET /readyz: %v", err)
+	}
+	defer httpResp.Body.Close()
+	var report HealthReport
+	assert.NoError(t, json.NewDecoder(httpResp.Body).Decode(&report))
+	assert.Equal(t, http.StatusServiceUnavailable, httpResp.StatusCode)
+	assert.Equal(t, "error", report.Checks["db"].Status)
+	assert.Equal(t, "ok", report.Checks["static"].Status)
+}
+
+func TestBuilder_HealthChecks_MissingDependency(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		AddHealthCheckFunc(func(c *toggleCheck) HealthChecker { return c }).
+		Build()
+
+	assert.ErrorContains(t, err, "health check DI failed")
+	assert.ErrorContains(t, err, "no provider for *server.toggleCheck (requested by group member of []server.HealthChecker)")
+}
+
+// pingMongo is an odm.MongoClient whose Ping returns err.
+type pingMongo struct {
+	odm.MongoClient
+	err error
+}
+
+func (m *pingMongo) Ping(context.Context, *readpref.ReadPref) error { return m.err }
+
+func TestMongoHealthCheck_Ping(t *testing.T) {
+	client := &pingMongo{err: errors.New("ping failed")}
+	check := MongoHealthCheck(client)
+
+	assert.Equal(t, "mongo", check.Name())
+	assert.ErrorContains(t, check.Check(context.Background()), "ping failed")
+	client.err = nil
+	assert.NoError(t, check.Check(context.Background()))
+}
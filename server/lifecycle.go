@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	"go.uber.org/zap"
+)
+
+const defaultLifecycleTimeout = 30 * time.Second
+
+// Starter is implemented by DI-managed components that must be initialised
+// before the server accepts traffic (e.g. warming caches, starting consumers).
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by DI-managed components that must release resources
+// on shutdown (e.g. Mongo Disconnect, flushing buffered writers).
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// lifecycle runs Start/Stop hooks of resolved components. Components are kept
+// in dependency order: a component always appears after everything it depends on.
+type lifecycle struct {
+	mu         sync.Mutex
+	components []any
+	timeout    time.Duration
+	started    int  // number of components whose Start has been run (or skipped)
+	running    bool // start succeeded and stop hasn't run
+
+	stopOnce sync.Once
+	stopErr  error
+}
+
+func newLifecycle(values []reflect.Value, timeout time.Duration) *lifecycle {
+	if timeout <= 0 {
+		timeout = defaultLifecycleTimeout
+	}
+	return &lifecycle{timeout: timeout, components: hookedComponents(values)}
+}
+
+// hookedComponents returns the values implementing Starter or Stopper.
+func hookedComponents(values []reflect.Value) []any {
+	var out []any
+	for _, v := range values {
+		if !v.IsValid() || !v.CanInterface() {
+			continue
+		}
+		c := v.Interface()
+		_, isStarter := c.(Starter)
+		_, isStopper := c.(Stopper)
+		if isStarter || isStopper {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// start runs Start hooks in dependency order. On the first failure, components
+// that were already started are stopped in reverse order and the errors are joined.
+func (l *lifecycle) start(ctx context.Context) error {
+	if err := l.startAll(ctx); err != nil {
+		return errors.Join(err, l.stop(context.WithoutCancel(ctx)))
+	}
+	return nil
+}
+
+func (l *lifecycle) startAll(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, c := range l.components {
+		if s, ok := c.(Starter); ok {
+			if err := l.runHook(ctx, c, s.Start); err != nil {
+				l.started = i
+				return fmt.Errorf("start %T: %w", c, err)
+			}
+		}
+	}
+	l.started = len(l.components)
+	l.running = true
+	return nil
+}
+
+// add takes components resolved after Build, such as a singleton first
+// requested inside a request scope. While the lifecycle is running they are
+// started right away; either way stop stops them with the rest.
+func (l *lifecycle) add(ctx context.Context, values []reflect.Value) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, c := range hookedComponents(values) {
+		if !l.running {
+			l.components = append(l.components, c) // start will start it
+			continue
+		}
+		if s, ok := c.(Starter); ok {
+			if err := l.runHook(ctx, c, s.Start); err != nil {
+				return fmt.Errorf("start %T: %w", c, err)
+			}
+		}
+		l.components = append(l.components, c)
+		l.started = len(l.components)
+	}
+	return nil
+}
+
+// stop runs Stop hooks in reverse dependency order: those of started
+// components, and those of components without a Start hook, such as provider
+// cleanups, even if start never ran. Every hook is called even if earlier ones
+// fail; all errors are aggregated. Only the first call stops anything; later
+// calls return its result.
+func (l *lifecycle) stop(ctx context.Context) error {
+	l.stopOnce.Do(func() { l.stopErr = l.stopAll(ctx) })
+	return l.stopErr
+}
+
+func (l *lifecycle) stopAll(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running = false
+	var errs []error
+	for i := len(l.components) - 1; i >= 0; i-- {
+		c := l.components[i]
+		if _, isStarter := c.(Starter); isStarter && i >= l.started {
+			continue
+		}
+		if s, ok := c.(Stopper); ok {
+			if err := l.runHook(ctx, c, s.Stop); err != nil {
+				errs = append(errs, fmt.Errorf("stop %T: %w", c, err))
+			}
+		}
+	}
+	l.started = 0
+	return errors.Join(errs...)
+}
+
+// runHook bounds a single hook with the configured timeout. A hook that ignores
+// its context is abandoned once the timeout elapses.
+func (l *lifecycle) runHook(ctx context.Context, c any, hook func(context.Context) error) error {
+	hookCtx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- hook(hookCtx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-hookCtx.Done():
+		logger.Error("Lifecycle hook timed out", zap.String("component", fmt.Sprintf("%T", c)), zap.Duration("timeout", l.timeout))
+		return hookCtx.Err()
+	}
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hookRecorder collects the order in which lifecycle hooks run.
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *hookRecorder) add(e string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *hookRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+type hookComp struct {
+	name     string
+	rec      *hookRecorder
+	startErr error
+	stopErr  error
+	block    bool // ignore ctx and block in Start
+}
+
+func (c *hookComp) Start(ctx context.Context) error {
+	if c.block {
+		time.Sleep(time.Second)
+	}
+	c.rec.add("start:" + c.name)
+	return c.startErr
+}
+
+func (c *hookComp) Stop(ctx context.Context) error {
+	c.rec.add("stop:" + c.name)
+	return c.stopErr
+}
+
+// stopOnly implements only Stopper.
+type stopOnly struct{ rec *hookRecorder }
+
+func (s *stopOnly) Stop(ctx context.Context) error {
+	s.rec.add("stop:stopOnly")
+	return nil
+}
+
+func values(vs ...any) []reflect.Value {
+	out := make([]reflect.Value, len(vs))
+	for i, v := range vs {
+		out[i] = reflect.ValueOf(v)
+	}
+	return out
+}
+
+func TestLifecycle_StartInOrder_StopInReverse(t *testing.T) {
+	rec := &hookRecorder{}
+	l := newLifecycle(values(
+		&hookComp{name: "a", rec: rec},
+		&dep{id: 1}, // no hooks – ignored
+		&stopOnly{rec: rec},
+		&hookComp{name: "b", rec: rec},
+	), time.Second)
+
+	assert.Len(t, l.components, 3)
+	assert.NoError(t, l.start(context.Background()))
+	assert.NoError(t, l.stop(context.Background()))
+
+	assert.Equal(t, []string{"start:a", "start:b", "stop:b", "stop:stopOnly", "stop:a"}, rec.snapshot())
+}
+
+func TestLifecycle_StopWithoutStart_RunsStopOnlyHooksOnce(t *testing.T) {
+	rec := &hookRecorder{}
+	l := newLifecycle(values(&hookComp{name: "a", rec: rec}, &stopOnly{rec: rec}), time.Second)
+
+	assert.NoError(t, l.stop(context.Background()))
+	assert.NoError(t, l.stop(context.Background()))
+
+	assert.Equal(t, []string{"stop:stopOnly"}, rec.snapshot(), "a is never started, so it isn't stopped")
+}
+
+func TestLifecycle_StartFailure_StopsStartedComponents(t *testing.T) {
+	rec := &hookRecorder{}
+	l := newLifecycle(values(
+		&hookComp{name: "a", rec: rec},
+		&hookComp{name: "b", rec: rec, startErr: errors.New("boom")},
+		&hookComp{name: "c", rec: rec},
+	), time.Second)
+
+	err := l.start(context.Background())
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, []string{"start:a", "start:b", "stop:a"}, rec.snapshot())
+}
+
+func TestLifecycle_Stop_AggregatesErrors(t *testing.T) {
+	rec := &hookRecorder{}
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	l := newLifecycle(values(
+		&hookComp{name: "a", rec: rec, stopErr: errA},
+		&hookComp{name: "b", rec: rec, stopErr: errB},
+	), time.Second)
+
+	assert.NoError(t, l.start(context.Background()))
+	err := l.stop(context.Background())
+
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+	assert.Equal(t, []string{"start:a", "start:b", "stop:b", "stop:a"}, rec.snapshot())
+}
+
+func TestLifecycle_HookTimeout(t *testing.T) {
+	l := newLifecycle(values(&hookComp{name: "slow", rec: &hookRecorder{}, block: true}), 20*time.Millisecond)
+
+	err := l.start(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// hookedDep is provided from *hookComp, so it must start after it.
+type hookedDep struct {
+	hookComp
+}
+
+func TestBuilder_Serve_RunsLifecycleHooks(t *testing.T) {
+	rec := &hookRecorder{}
+	base := &hookComp{name: "base", rec: rec}
+
+	boot, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		Provide(base).
+		ProvideFunc(func(b *hookComp) *hookedDep {
+			return &hookedDep{hookComp: hookComp{name: "derived", rec: rec}}
+		}).
+		RegisterService((&regSpy{}).fn, func(h *hookedDep) *svc { return &svc{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- boot.Serve(ctx) }()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{"start:base", "start:derived"}, rec.snapshot())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve(ctx) did not return after cancellation")
+	}
+
+	assert.Equal(t, []string{"start:base", "start:derived", "stop:derived", "stop:base"}, rec.snapshot())
+}
+
+func TestBuilder_Serve_StartFailure_ReleasesListeners(t *testing.T) {
+	rec := &hookRecorder{}
+	boot, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		Provide(&hookComp{name: "base", rec: rec}).
+		ProvideFunc(func(b *hookComp) *hookedDep {
+			return &hookedDep{hookComp: hookComp{name: "derived", rec: rec, startErr: errors.New("boom")}}
+		}).
+		RegisterService((&regSpy{}).fn, func(h *hookedDep) *svc { return &svc{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	err = boot.Serve(context.Background())
+	assert.ErrorContains(t, err, "lifecycle start failed")
+	assert.Equal(t, []string{"start:base", "start:derived", "stop:base"}, rec.snapshot())
+
+	for _, addr := range []string{boot.lnGrpc.Addr().String(), boot.lnHTTP.Addr().String()} {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatalf("port %s still bound: %v", addr, err)
+		}
+		ln.Close()
+	}
+}
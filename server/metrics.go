@@ -0,0 +1,311 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcWebHeader marks requests forwarded by the web proxy, so metrics can
+// tell gRPC-Web calls from native gRPC.
+const grpcWebHeader = "x-grpc-web"
+
+var registererType = reflect.TypeOf((*prometheus.Registerer)(nil)).Elem()
+
+var sizeBuckets = prometheus.ExponentialBuckets(64, 4, 9) // 64B … 4MB
+
+// requestMetrics records request counts, latency, in-flight requests and
+// message sizes for gRPC methods and HTTP routes, plus recovered panics and
+// rate-limited requests.
+type requestMetrics struct {
+	grpcHandled  *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	grpcInFlight *prometheus.GaugeVec
+	grpcRecvSize *prometheus.HistogramVec
+	grpcSentSize *prometheus.HistogramVec
+
+	httpHandled  *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight *prometheus.GaugeVec
+	httpReqSize  *prometheus.HistogramVec
+	httpRespSize *prometheus.HistogramVec
+
+	panicsRecovered *prometheus.CounterVec
+	rateLimited     *prometheus.CounterVec
+}
+
+// newRequestMetrics registers the collectors with reg. Collectors already
+// registered by an earlier Build are reused; a different collector under one
+// of the names is an error.
+func newRequestMetrics(reg prometheus.Registerer) (*requestMetrics, error) {
+	grpcLabels := []string{"service", "method", "web"}
+	httpLabels := []string{"route", "method"}
+	r := &collectorSet{reg: reg}
+	rm := &requestMetrics{
+		grpcHandled: register(r, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_api_boot_grpc_requests_total",
+			Help: "gRPC calls completed, by status code.",
+		}, append(grpcLabels, "code"))),
+		grpcDuration: register(r, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_api_boot_grpc_request_duration_seconds",
+			Help:    "gRPC call latency.",
+			Buckets: prometheus.DefBuckets,
+		}, grpcLabels)),
+		grpcInFlight: register(r, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "go_api_boot_grpc_requests_in_flight",
+			Help: "gRPC calls currently being handled.",
+		}, grpcLabels)),
+		grpcRecvSize: register(r, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_api_boot_grpc_received_message_bytes",
+			Help:    "Size of received gRPC messages.",
+			Buckets: sizeBuckets,
+		}, grpcLabels)),
+		grpcSentSize: register(r, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_api_boot_grpc_sent_message_bytes",
+			Help:    "Size of sent gRPC messages.",
+			Buckets: sizeBuckets,
+		}, grpcLabels)),
+
+		httpHandled: register(r, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_api_boot_http_requests_total",
+			Help: "HTTP requests completed, by status code.",
+		}, append(httpLabels, "code"))),
+		httpDuration: register(r, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_api_boot_http_request_duration_seconds",
+			Help:    "HTTP request latency.",
+			Buckets: prometheus.DefBuckets,
+		}, httpLabels)),
+		httpInFlight: register(r, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "go_api_boot_http_requests_in_flight",
+			Help: "HTTP requests currently being handled.",
+		}, []string{"route"})),
+		httpReqSize: register(r, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_api_boot_http_request_bytes",
+			Help:    "Size of HTTP request bodies with a known Content-Length.",
+			Buckets: sizeBuckets,
+		}, httpLabels)),
+		httpRespSize: register(r, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "go_api_boot_http_response_bytes",
+			Help:    "Size of HTTP response bodies.",
+			Buckets: sizeBuckets,
+		}, httpLabels)),
+
+		panicsRecovered: register(r, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_api_boot_panics_recovered_total",
+			Help: "Panics recovered in gRPC and HTTP handlers.",
+		}, []string{"transport", "method"})),
+		rateLimited: register(r, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "go_api_boot_rate_limited_total",
+			Help: "Requests rejected by a rate limit.",
+		}, []string{"transport", "method"})),
+	}
+	return rm, r.err
+}
+
+// collectorSet registers collectors with reg and keeps the first error.
+type collectorSet struct {
+	reg prometheus.Registerer
+	err error
+}
+
+func register[C prometheus.Collector](r *collectorSet, c C) C {
+	if err := r.reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		if r.err == nil {
+			r.err = err // conflicting definition of a go_api_boot metric
+		}
+	}
+	return c
+}
+
+// metricsHandler serves the metrics gathered by reg. A custom registry also
+// gets the Go runtime and process collectors the default registry has.
+func metricsHandler(reg prometheus.Registerer) (http.Handler, error) {
+	g, ok := reg.(prometheus.Gatherer)
+	if !ok || reg == prometheus.DefaultRegisterer {
+		return promhttp.Handler(), nil
+	}
+	r := &collectorSet{reg: reg}
+	register(r, collectors.NewGoCollector())
+	register(r, collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return promhttp.HandlerFor(g, promhttp.HandlerOpts{}), r.err
+}
+
+// splitMethod turns "/pkg.Service/Method" into its service and method.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+func isGRPCWeb(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return strconv.FormatBool(len(md.Get(grpcWebHeader)) > 0)
+}
+
+func messageSize(m any) (float64, bool) {
+	if pm, ok := m.(proto.Message); ok {
+		return float64(proto.Size(pm)), true
+	}
+	return 0, false
+}
+
+func (m *requestMetrics) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		service, method := splitMethod(info.FullMethod)
+		web := isGRPCWeb(ctx)
+
+		inFlight := m.grpcInFlight.WithLabelValues(service, method, web)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		if n, ok := messageSize(req); ok {
+			m.grpcRecvSize.WithLabelValues(service, method, web).Observe(n)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.grpcDuration.WithLabelValues(service, method, web).Observe(time.Since(start).Seconds())
+		m.grpcHandled.WithLabelValues(service, method, web, status.Code(err).String()).Inc()
+
+		if n, ok := messageSize(resp); ok && err == nil {
+			m.grpcSentSize.WithLabelValues(service, method, web).Observe(n)
+		}
+		return resp, err
+	}
+}
+
+func (m *requestMetrics) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		service, method := splitMethod(info.FullMethod)
+		web := isGRPCWeb(ss.Context())
+
+		inFlight := m.grpcInFlight.WithLabelValues(service, method, web)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		err := handler(srv, &meteredStream{
+			ServerStream: ss,
+			recv:         m.grpcRecvSize.WithLabelValues(service, method, web),
+			sent:         m.grpcSentSize.WithLabelValues(service, method, web),
+		})
+		m.grpcDuration.WithLabelValues(service, method, web).Observe(time.Since(start).Seconds())
+		m.grpcHandled.WithLabelValues(service, method, web, status.Code(err).String()).Inc()
+		return err
+	}
+}
+
+// meteredStream observes the size of every streamed message.
+type meteredStream struct {
+	grpc.ServerStream
+	recv, sent prometheus.Observer
+}
+
+func (s *meteredStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if n, ok := messageSize(m); ok && err == nil {
+		s.sent.Observe(n)
+	}
+	return err
+}
+
+func (s *meteredStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if n, ok := messageSize(m); ok && err == nil {
+		s.recv.Observe(n)
+	}
+	return err
+}
+
+// middleware records metrics for an HTTP route; route is the registered
+// pattern, never the raw path, to keep label cardinality bounded.
+func (m *requestMetrics) middleware(route string, next http.Handler) http.Handler {
+	inFlight := m.httpInFlight.WithLabelValues(route)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		if r.ContentLength > 0 {
+			m.httpReqSize.WithLabelValues(route, r.Method).Observe(float64(r.ContentLength))
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		start := time.Now()
+		defer func() {
+			m.httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+			m.httpHandled.WithLabelValues(route, r.Method, strconv.Itoa(sw.code())).Inc()
+			m.httpRespSize.WithLabelValues(route, r.Method).Observe(float64(sw.bytes))
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// statusWriter captures the status code and body size of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush keeps streaming responses (gRPC-Web, SSE) working through the wrapper.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the web proxy upgrade to a WebSocket through the wrapper.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *statusWriter) code() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSplitMethod(t *testing.T) {
+	service, method := splitMethod("/shop.Orders/List")
+	assert.Equal(t, "shop.Orders", service)
+	assert.Equal(t, "List", method)
+
+	service, method = splitMethod("bogus")
+	assert.Equal(t, "unknown", service)
+	assert.Equal(t, "bogus", method)
+}
+
+func TestRequestMetrics_UnaryInterceptor(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	rm, err := newRequestMetrics(reg)
+	assert.NoError(t, err)
+	interceptor := rm.unaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/shop.Orders/Get"}
+
+	_, err = interceptor(context.Background(), wrapperspb.String("id-1"), info,
+		func(context.Context, any) (any, error) { return wrapperspb.String("order"), nil })
+	assert.NoError(t, err)
+
+	webCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcWebHeader, "1"))
+	_, err = interceptor(webCtx, wrapperspb.String("id-2"), info,
+		func(context.Context, any) (any, error) { return nil, status.Error(codes.NotFound, "no order") })
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.Equal(t, 1.0, promtest.ToFloat64(rm.grpcHandled.WithLabelValues("shop.Orders", "Get", "false", "OK")))
+	assert.Equal(t, 1.0, promtest.ToFloat64(rm.grpcHandled.WithLabelValues("shop.Orders", "Get", "true", "NotFound")))
+	assert.Equal(t, 0.0, promtest.ToFloat64(rm.grpcInFlight.WithLabelValues("shop.Orders", "Get", "false")))
+	assert.Equal(t, 3, promtest.CollectAndCount(rm.grpcRecvSize)+promtest.CollectAndCount(rm.grpcSentSize))
+}
+
+func TestRequestMetrics_Middleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	rm, _ := newRequestMetrics(reg)
+	h := rm.middleware("/orders/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, 1.0, promtest.ToFloat64(rm.httpHandled.WithLabelValues("/orders/{id}", http.MethodGet, "418")))
+	assert.Equal(t, 0.0, promtest.ToFloat64(rm.httpInFlight.WithLabelValues("/orders/{id}")))
+}
+
+func TestNewRequestMetrics_ReusesRegisteredCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, _ := newRequestMetrics(reg)
+	second, err := newRequestMetrics(reg)
+	assert.NoError(t, err)
+	assert.Same(t, first.grpcHandled, second.grpcHandled)
+	assert.Same(t, first.httpDuration, second.httpDuration)
+}
+
+func TestBuilder_ConflictingMetricFailsBuild(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "go_api_boot_grpc_requests_total",
+		Help: "Something else.",
+	}))
+
+	_, err := New().HTTPPort(":0").ProvideAs(reg, (*prometheus.Registerer)(nil)).Build()
+	assert.ErrorContains(t, err, "metrics registration failed")
+}
+
+// ordersController counts orders on a business metric that shares the
+// server's Registerer.
+type ordersController struct {
+	placed prometheus.Counter
+}
+
+func (c *ordersController) Routes() []Route {
+	return []Route{{Pattern: "/orders", Method: http.MethodPost, Handler: func(w http.ResponseWriter, r *http.Request) {
+		c.placed.Inc()
+		w.WriteHeader(http.StatusCreated)
+	}}}
+}
+
+func newOrdersController(reg prometheus.Registerer) *ordersController {
+	placed := prometheus.NewCounter(prometheus.CounterOpts{Name: "orders_placed_total", Help: "Orders placed."})
+	reg.MustRegister(placed)
+	return &ordersController{placed: placed}
+}
+
+func TestBuilder_Metrics_CustomRegisterer(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	bs, err := New().
+		HTTPPort(":0").
+		ProvideAs(reg, (*prometheus.Registerer)(nil)).
+		AddRestController(newOrdersController).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	rec := httptest.NewRecorder()
+	bs.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = httptest.NewRecorder()
+	bs.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	assert.Contains(t, string(body), "orders_placed_total 1")
+	assert.Contains(t, string(body), `go_api_boot_http_requests_total{code="201",method="POST",route="/orders"} 1`)
+	assert.Contains(t, string(body), "go_goroutines") // runtime metrics are added to custom registries
+}
+
+func TestBuilder_Metrics_DefaultRegistererCanBuildTwice(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		bs, err := New().
+			HTTPPort(":0").
+			AddRestController(func(reg prometheus.Registerer) *testRestController {
+				assert.Equal(t, prometheus.DefaultRegisterer, reg)
+				return &testRestController{}
+			}).
+			Build()
+		if err != nil {
+			t.Fatalf("Build() failed: %v", err)
+		}
+		bs.lnHTTP.Close()
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"github.com/SaiNageswarS/go-api-boot/cloud"
+	"github.com/SaiNageswarS/go-api-boot/odm"
+)
+
+// Module packages reusable wiring – providers, services, REST controllers,
+// interceptors, Temporal activities – so services don't repeat the same
+// Provide/ProvideFunc chain. Modules are installed once per Builder, keyed by
+// Name, no matter how many modules depend on them.
+type Module interface {
+	Name() string
+	Configure(b *Builder)
+}
+
+// moduleDeps is implemented by modules that must be installed after others.
+type moduleDeps interface {
+	DependsOn() []Module
+}
+
+type funcModule struct {
+	name      string
+	configure func(*Builder)
+	deps      []Module
+}
+
+func (m *funcModule) Name() string         { return m.name }
+func (m *funcModule) Configure(b *Builder) { m.configure(b) }
+func (m *funcModule) DependsOn() []Module  { return m.deps }
+
+// NewModule creates a Module from a configure func. deps are installed first.
+//
+// Example:
+//
+//	var AuthModule = server.NewModule("auth", func(b *server.Builder) {
+//	    b.ProvideFunc(NewUserRepository).
+//	        RegisterService(server.Adapt(pb.RegisterLoginServer), ProvideLoginService)
+//	}, server.MongoModule)
+func NewModule(name string, configure func(*Builder), deps ...Module) Module {
+	return &funcModule{name: name, configure: configure, deps: deps}
+}
+
+// Install configures the builder with each module and its dependencies.
+// A module already installed (by name) is skipped.
+func (b *Builder) Install(modules ...Module) *Builder {
+	for _, m := range modules {
+		if b.installed[m.Name()] {
+			continue
+		}
+		// mark first so mutually dependent modules don't recurse forever
+		b.installed[m.Name()] = true
+
+		if d, ok := m.(moduleDeps); ok {
+			b.Install(d.DependsOn()...)
+		}
+		m.Configure(b)
+	}
+	return b
+}
+
+// The ready-made modules below live here rather than in odm and cloud, which
+// would otherwise have to import server to configure a Builder.
+
+// AzureModule binds cloud.Cloud to Azure. Requires *config.BootConfig.
+var AzureModule = NewModule("cloud.azure", func(b *Builder) {
+	b.ProvideFunc(cloud.ProvideAzure)
+})
+
+// GCPModule binds cloud.Cloud to GCP. Requires *config.BootConfig.
+var GCPModule = NewModule("cloud.gcp", func(b *Builder) {
+	b.ProvideFunc(cloud.ProvideGCP)
+})
+
+// MongoModule provides an odm.MongoClient connected via MONGO_URI and
+// registers its health check. The client is disconnected when the server
+// stops.
+var MongoModule = NewModule("odm.mongo", func(b *Builder) {
+	b.ProvideFunc(odm.NewMongoClient).AddHealthCheckFunc(MongoHealthCheck)
+})
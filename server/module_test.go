@@ -0,0 +1,65 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_Install_DepsFirstAndDeduplicated(t *testing.T) {
+	var order []string
+	base := NewModule("base", func(b *Builder) { order = append(order, "base") })
+	left := NewModule("left", func(b *Builder) { order = append(order, "left") }, base)
+	right := NewModule("right", func(b *Builder) { order = append(order, "right") }, base)
+
+	b := New().Install(left, right).Install(base)
+
+	assert.Equal(t, []string{"base", "left", "right"}, order)
+	assert.Len(t, b.installed, 3)
+}
+
+func TestBuilder_Install_NestedInstall(t *testing.T) {
+	var order []string
+	inner := NewModule("inner", func(b *Builder) { order = append(order, "inner") })
+	outer := NewModule("outer", func(b *Builder) {
+		b.Install(inner)
+		order = append(order, "outer")
+	})
+
+	New().Install(outer, inner)
+
+	assert.Equal(t, []string{"inner", "outer"}, order)
+}
+
+func TestBuilder_Install_CyclicModulesTerminate(t *testing.T) {
+	var a, b Module
+	calls := 0
+	a = &funcModule{name: "a", configure: func(*Builder) { calls++ }}
+	b = NewModule("b", func(*Builder) { calls++ }, a)
+	a.(*funcModule).deps = []Module{b}
+
+	New().Install(a)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestBuilder_Install_RegistersProviders(t *testing.T) {
+	spy := &regSpy{}
+	p := &depProvider{}
+	deps := NewModule("deps", func(b *Builder) { b.ProvideFunc(p.provide) })
+	svcs := NewModule("svcs", func(b *Builder) {
+		b.RegisterService(spy.fn, func(d *dep) *svc { return &svc{} })
+	}, deps)
+
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		Install(svcs).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	assert.IsType(t, &svc{}, spy.gotSrv)
+	assert.Equal(t, 1, p.called)
+}
@@ -0,0 +1,65 @@
+package server
+
+import "reflect"
+
+// Qualifier names a dependency registered with ProvideNamed / ProvideFuncNamed.
+// Implement it on an empty struct and use that struct as the Tag of Named.
+//
+// Example:
+//
+//	type Analytics struct{}
+//	func (Analytics) Name() string { return "analytics" }
+type Qualifier interface {
+	Name() string
+}
+
+// Named requests the dependency of type T registered under Tag's name.
+// Use it as a factory parameter to pick one of several registrations of T.
+//
+// Example:
+//
+//	builder.
+//	    ProvideAs(primary, (*odm.MongoClient)(nil)).
+//	    ProvideNamedAs("analytics", analytics, (*odm.MongoClient)(nil)).
+//	    RegisterService(server.Adapt(pb.RegisterReportServer),
+//	        func(db server.Named[odm.MongoClient, Analytics]) *ReportService {
+//	            return &ReportService{mongo: db.Value}
+//	        })
+type Named[T any, Tag Qualifier] struct {
+	Value T
+}
+
+func (Named[T, Tag]) qualifier() (string, reflect.Type) {
+	var tag Tag
+	return tag.Name(), reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// namedDependency is implemented by every instantiation of Named.
+type namedDependency interface {
+	qualifier() (string, reflect.Type)
+}
+
+var namedDependencyType = reflect.TypeOf((*namedDependency)(nil)).Elem()
+
+// namedKey identifies a named registration.
+type namedKey struct {
+	name string
+	typ  reflect.Type
+}
+
+// asNamed reports whether t is a Named[T, Tag] parameter and returns the
+// name and the wrapped type T.
+func asNamed(t reflect.Type) (namedKey, bool) {
+	if t.Kind() != reflect.Struct || !t.Implements(namedDependencyType) {
+		return namedKey{}, false
+	}
+	name, inner := reflect.Zero(t).Interface().(namedDependency).qualifier()
+	return namedKey{name: name, typ: inner}, true
+}
+
+// wrapNamed builds a Named[T, Tag] value of type t holding v.
+func wrapNamed(t reflect.Type, v reflect.Value) reflect.Value {
+	out := reflect.New(t).Elem()
+	out.Field(0).Set(v)
+	return out
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"path"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
+)
+
+// methodSet matches full gRPC method names ("/pkg.Service/Method") against
+// exact names and path.Match globs.
+type methodSet struct {
+	exact    map[string]bool
+	patterns []string
+}
+
+func newMethodSet() *methodSet {
+	return &methodSet{exact: map[string]bool{}}
+}
+
+func (s *methodSet) add(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	if isGlob(pattern) {
+		s.patterns = append(s.patterns, pattern)
+	} else {
+		s.exact[pattern] = true
+	}
+	return nil
+}
+
+func (s *methodSet) match(fullMethod string) bool {
+	if s.exact[fullMethod] {
+		return true
+	}
+	for _, p := range s.patterns {
+		if ok, _ := path.Match(p, fullMethod); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isGlob(p string) bool {
+	for _, c := range p {
+		switch c {
+		case '*', '?', '[', '\\':
+			return true
+		}
+	}
+	return false
+}
+
+// authUnaryInterceptor verifies tokens with authFn like grpc_auth, except for
+// public methods: those run without a token, but a token that is present is
+// still parsed so handlers see the caller's claims.
+func authUnaryInterceptor(public *methodSet, authFn grpc_auth.AuthFunc) grpc.UnaryServerInterceptor {
+	strict := grpc_auth.UnaryServerInterceptor(authFn)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if public.match(info.FullMethod) {
+			return handler(optionalAuth(ctx, authFn), req)
+		}
+		return strict(ctx, req, info, handler)
+	}
+}
+
+func authStreamInterceptor(public *methodSet, authFn grpc_auth.AuthFunc) grpc.StreamServerInterceptor {
+	strict := grpc_auth.StreamServerInterceptor(authFn)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public.match(info.FullMethod) {
+			wrapped := grpc_middleware.WrapServerStream(ss)
+			wrapped.WrappedContext = optionalAuth(ss.Context(), authFn)
+			return handler(srv, wrapped)
+		}
+		return strict(srv, ss, info, handler)
+	}
+}
+
+// optionalAuth applies authFn when the request carries a bearer token and
+// keeps the request anonymous when it does not or the token is invalid.
+func optionalAuth(ctx context.Context, authFn grpc_auth.AuthFunc) context.Context {
+	if _, err := grpc_auth.AuthFromMD(ctx, "bearer"); err != nil {
+		return ctx
+	}
+	if newCtx, err := authFn(ctx); err == nil {
+		return newCtx
+	}
+	return ctx
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SaiNageswarS/go-api-boot/auth"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMethodSet_Match(t *testing.T) {
+	s := newMethodSet()
+	assert.NoError(t, s.add("/auth.Login/Login"))
+	assert.NoError(t, s.add("/public.*/*"))
+	assert.Error(t, s.add("/bad[pattern"))
+
+	assert.True(t, s.match("/auth.Login/Login"))
+	assert.False(t, s.match("/auth.Login/ChangePassword"))
+	assert.True(t, s.match("/public.Catalog/List"))
+	assert.False(t, s.match("/private.Catalog/List"))
+}
+
+// callWithAuth runs the default unary chain of a builder for method and
+// returns the user id the handler saw.
+func callWithAuth(t *testing.T, b *Builder, method, token string) (string, error) {
+	t.Helper()
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "bearer "+token))
+	}
+
+	var userID string
+	_, err := authUnaryInterceptor(b.publicMethods, auth.VerifyTokenGrpcMiddleware())(
+		ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(ctx context.Context, _ any) (any, error) {
+			userID, _ = auth.GetUserIdAndTenant(ctx)
+			return nil, nil
+		})
+	return userID, err
+}
+
+func TestBuilder_PublicMethods_SkipAuth(t *testing.T) {
+	t.Setenv("ACCESS-SECRET", "test-secret")
+	token, err := auth.GetToken("tenant", "rick", "user")
+	assert.NoError(t, err)
+
+	b := New().PublicMethods("/auth.Login/Login", "/auth.Catalog/*")
+
+	// public: no token required
+	_, err = callWithAuth(t, b, "/auth.Login/Login", "")
+	assert.NoError(t, err)
+	_, err = callWithAuth(t, b, "/auth.Catalog/List", "")
+	assert.NoError(t, err)
+
+	// public: token still parsed when present, invalid token ignored
+	user, err := callWithAuth(t, b, "/auth.Login/Login", token)
+	assert.NoError(t, err)
+	assert.Equal(t, "rick", user)
+	user, err = callWithAuth(t, b, "/auth.Login/Login", "garbage")
+	assert.NoError(t, err)
+	assert.Empty(t, user)
+
+	// protected method on the same service
+	_, err = callWithAuth(t, b, "/auth.Login/ChangePassword", "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	user, err = callWithAuth(t, b, "/auth.Login/ChangePassword", token)
+	assert.NoError(t, err)
+	assert.Equal(t, "rick", user)
+}
+
+func TestBuilder_PublicMethods_InvalidPattern(t *testing.T) {
+	mockLogger := withMockLogger(func() {
+		New().PublicMethods("/auth.Login/[")
+	})
+	assert.True(t, mockLogger.isFatalCalled)
+	assert.Equal(t, "Invalid public method pattern", mockLogger.fatalMsg)
+}
+
+func TestAuthStreamInterceptor_PublicMethods(t *testing.T) {
+	b := New().PublicMethods("/auth.Feed/Subscribe")
+	interceptor := authStreamInterceptor(b.publicMethods, auth.VerifyTokenGrpcMiddleware())
+	ss := &scopeTestStream{ctx: context.Background()}
+	handler := func(any, grpc.ServerStream) error { return nil }
+
+	assert.NoError(t, interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/auth.Feed/Subscribe"}, handler))
+
+	err := interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/auth.Feed/Publish"}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
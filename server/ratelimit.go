@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/auth"
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimitKey selects whose requests share a limit.
+type RateLimitKey int
+
+const (
+	// RateLimitByTenant shares the limit across a tenant (auth.TENANT_CLAIM).
+	RateLimitByTenant RateLimitKey = iota
+	// RateLimitByUser gives every user (auth.USER_ID_CLAIM) its own limit.
+	RateLimitByUser
+	// RateLimitByIP limits each client address.
+	RateLimitByIP
+)
+
+// RateLimitStrategy is how a RateLimitStore counts requests.
+type RateLimitStrategy int
+
+const (
+	// TokenBucket allows bursts of up to Burst requests, refilled at Limit per Window.
+	TokenBucket RateLimitStrategy = iota
+	// SlidingWindow allows Limit requests in any Window, weighting the previous
+	// window by how much of it still overlaps.
+	SlidingWindow
+)
+
+// RateLimit allows Limit requests per Window for each caller selected by Key.
+// Callers without the claim Key needs are limited by IP. A zero RateLimit
+// means no limit.
+type RateLimit struct {
+	Key      RateLimitKey
+	Strategy RateLimitStrategy
+	Limit    int
+	Window   time.Duration
+	// Burst is the TokenBucket capacity; defaults to Limit.
+	Burst int
+}
+
+func (l RateLimit) isZero() bool { return l == RateLimit{} }
+
+func (l RateLimit) validate() error {
+	switch {
+	case l.Limit <= 0:
+		return errors.New("limit must be positive")
+	case l.Window <= 0:
+		return errors.New("window must be positive")
+	case l.Burst < 0:
+		return errors.New("burst must not be negative")
+	}
+	return nil
+}
+
+// RateLimitStore decides whether one more request for key fits limit. When it
+// does not, retryAfter tells the caller when to try again. The default store
+// is in memory, so every replica enforces its own limit; implement it on a
+// shared store such as Redis to enforce limits across replicas. Store errors
+// are logged and the request is let through.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// rateLimitRule maps a method pattern to its limit.
+type rateLimitRule struct {
+	pattern string
+	limit   RateLimit
+}
+
+// rateLimiter applies limits to gRPC methods and REST routes.
+type rateLimiter struct {
+	exact map[string]RateLimit
+	globs []rateLimitRule // checked in registration order
+	store RateLimitStore
+	// counts rejections
+	metrics *requestMetrics
+}
+
+func newRateLimiter(rm *requestMetrics) *rateLimiter {
+	return &rateLimiter{exact: map[string]RateLimit{}, store: NewMemoryRateLimitStore(), metrics: rm}
+}
+
+func (l *rateLimiter) add(pattern string, limit RateLimit) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	if err := limit.validate(); err != nil {
+		return err
+	}
+	if isGlob(pattern) {
+		l.globs = append(l.globs, rateLimitRule{pattern: pattern, limit: limit})
+	} else {
+		l.exact[pattern] = limit
+	}
+	return nil
+}
+
+func (l *rateLimiter) empty() bool { return len(l.exact) == 0 && len(l.globs) == 0 }
+
+// rule returns the limit for fullMethod and the pattern it was registered
+// under; methods matching one pattern share its budget.
+func (l *rateLimiter) rule(fullMethod string) (string, RateLimit, bool) {
+	if r, ok := l.exact[fullMethod]; ok {
+		return fullMethod, r, true
+	}
+	for _, g := range l.globs {
+		if ok, _ := path.Match(g.pattern, fullMethod); ok {
+			return g.pattern, g.limit, true
+		}
+	}
+	return "", RateLimit{}, false
+}
+
+// allow consults the store for the caller of ctx; remoteAddr is the fallback
+// key for anonymous callers.
+func (l *rateLimiter) allow(ctx context.Context, transport, method, pattern string, limit RateLimit, remoteAddr string) (bool, time.Duration) {
+	key := pattern + "|" + rateLimitSubject(ctx, limit.Key, remoteAddr)
+	ok, retryAfter, err := l.store.Allow(ctx, key, limit)
+	if err != nil {
+		logger.Error("Rate limit store failed, allowing request",
+			append([]zap.Field{zap.String("method", method), zap.Error(err)}, logger.ContextFields(ctx)...)...)
+		return true, 0
+	}
+	if !ok {
+		l.metrics.rateLimited.WithLabelValues(transport, method).Inc()
+	}
+	return ok, retryAfter
+}
+
+func rateLimitSubject(ctx context.Context, key RateLimitKey, remoteAddr string) string {
+	userID, tenant := auth.GetUserIdAndTenant(ctx)
+	switch key {
+	case RateLimitByTenant:
+		if tenant != "" {
+			return "tenant:" + tenant
+		}
+	case RateLimitByUser:
+		if userID != "" {
+			return "user:" + tenant + "/" + userID
+		}
+	}
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return "ip:" + host
+	}
+	return "ip:" + remoteAddr
+}
+
+// retryAfterSeconds rounds up to the whole seconds Retry-After carries.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
+
+func rateLimitError(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+	if withInfo, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func (l *rateLimiter) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if pattern, limit, ok := l.rule(info.FullMethod); ok {
+			if ok, retryAfter := l.allow(ctx, "grpc", info.FullMethod, pattern, limit, peerAddr(ctx)); !ok {
+				_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfterSeconds(retryAfter)))
+				return nil, rateLimitError(retryAfter)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (l *rateLimiter) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if pattern, limit, ok := l.rule(info.FullMethod); ok {
+			ctx := ss.Context()
+			if ok, retryAfter := l.allow(ctx, "grpc", info.FullMethod, pattern, limit, peerAddr(ctx)); !ok {
+				_ = ss.SetHeader(metadata.Pairs("retry-after", retryAfterSeconds(retryAfter)))
+				return rateLimitError(retryAfter)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// middleware applies route.RateLimit, answering 429 with Retry-After. It runs
+// after authorization, inside the token check of route.Require; Build rejects
+// tenant and user keys on routes without one.
+func (l *rateLimiter) middleware(route Route, next http.Handler) http.Handler {
+	if route.RateLimit.isZero() {
+		return next
+	}
+	method := route.Method + " " + route.Pattern
+	if route.Method == "" {
+		method = "* " + route.Pattern
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, retryAfter := l.allow(r.Context(), "http", method, method, route.RateLimit, r.RemoteAddr); !ok {
+			w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// sweepInterval is how often idle keys are dropped from the memory store.
+const sweepInterval = time.Minute
+
+// memoryRateLimitStore keeps the state of every key in process memory.
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	windows   map[string]*memoryWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryBucket struct {
+	lim      *rate.Limiter
+	lastUsed time.Time
+	refill   time.Duration // time for an empty bucket to fill up
+}
+
+type memoryWindow struct {
+	start      time.Time
+	prev, curr int
+	window     time.Duration
+}
+
+// NewMemoryRateLimitStore returns the default RateLimitStore. Limits are per
+// process: with N replicas a client gets up to N times the limit.
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{
+		buckets: map[string]*memoryBucket{},
+		windows: map[string]*memoryWindow{},
+		now:     time.Now,
+	}
+}
+
+func (s *memoryRateLimitStore) Allow(_ context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	if limit.Strategy == SlidingWindow {
+		ok, retryAfter := s.allowWindow(now, key, limit)
+		return ok, retryAfter, nil
+	}
+	ok, retryAfter := s.allowBucket(now, key, limit)
+	return ok, retryAfter, nil
+}
+
+func (s *memoryRateLimitStore) allowBucket(now time.Time, key string, limit RateLimit) (bool, time.Duration) {
+	b, ok := s.buckets[key]
+	if !ok {
+		burst := limit.Burst
+		if burst == 0 {
+			burst = limit.Limit
+		}
+		every := limit.Window / time.Duration(limit.Limit)
+		b = &memoryBucket{
+			lim:    rate.NewLimiter(rate.Every(every), burst),
+			refill: every * time.Duration(burst),
+		}
+		s.buckets[key] = b
+	}
+	b.lastUsed = now
+
+	r := b.lim.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// allowWindow approximates a sliding window from the counts of the current
+// and previous fixed windows.
+func (s *memoryRateLimitStore) allowWindow(now time.Time, key string, limit RateLimit) (bool, time.Duration) {
+	w, ok := s.windows[key]
+	if !ok {
+		w = &memoryWindow{start: now.Truncate(limit.Window), window: limit.Window}
+		s.windows[key] = w
+	}
+	if elapsed := now.Sub(w.start); elapsed >= w.window {
+		periods := elapsed / w.window
+		if periods == 1 {
+			w.prev = w.curr
+		} else {
+			w.prev = 0
+		}
+		w.curr = 0
+		w.start = w.start.Add(periods * w.window)
+	}
+
+	elapsed := now.Sub(w.start)
+	overlap := 1 - float64(elapsed)/float64(w.window)
+	if float64(w.prev)*overlap+float64(w.curr) < float64(limit.Limit) {
+		w.curr++
+		return true, 0
+	}
+
+	// wait until the previous window's weight has decayed enough, or for the
+	// next window when the current one alone is full
+	limitF, window := float64(limit.Limit), float64(w.window)
+	var wait time.Duration
+	if w.curr >= limit.Limit {
+		wait = w.window - elapsed + time.Duration(window*(1-limitF/float64(w.curr)))
+	} else {
+		wait = time.Duration(window*(1-(limitF-float64(w.curr))/float64(w.prev))) - elapsed
+	}
+	// the estimate must drop below the limit, not just reach it
+	return false, max(wait, 0).Truncate(time.Millisecond) + time.Millisecond
+}
+
+// sweep drops keys idle long enough that their state is back to empty.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for k, b := range s.buckets {
+		if now.Sub(b.lastUsed) > b.refill {
+			delete(s.buckets, k)
+		}
+	}
+	for k, w := range s.windows {
+		if now.Sub(w.start) > 2*w.window {
+			delete(s.windows, k)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClockStore returns a memory store whose clock the test advances.
+func fakeClockStore() (*memoryRateLimitStore, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryRateLimitStore().(*memoryRateLimitStore)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestMemoryRateLimitStore_TokenBucket(t *testing.T) {
+	s, now := fakeClockStore()
+	limit := RateLimit{Limit: 2, Window: time.Second}
+	allow := func() (bool, time.Duration) {
+		ok, retryAfter, err := s.Allow(context.Background(), "k", limit)
+		assert.NoError(t, err)
+		return ok, retryAfter
+	}
+
+	ok, _ := allow()
+	assert.True(t, ok)
+	ok, _ = allow()
+	assert.True(t, ok)
+	ok, retryAfter := allow()
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	*now = now.Add(500 * time.Millisecond)
+	ok, _ = allow()
+	assert.True(t, ok)
+}
+
+func TestMemoryRateLimitStore_SlidingWindow(t *testing.T) {
+	s, now := fakeClockStore()
+	limit := RateLimit{Strategy: SlidingWindow, Limit: 3, Window: time.Minute}
+	allow := func() (bool, time.Duration) {
+		ok, retryAfter, err := s.Allow(context.Background(), "k", limit)
+		assert.NoError(t, err)
+		return ok, retryAfter
+	}
+
+	for i := 0; i < 3; i++ {
+		ok, _ := allow()
+		assert.True(t, ok)
+	}
+	*now = now.Add(10 * time.Second)
+	ok, retryAfter := allow()
+	assert.False(t, ok)
+	assert.Equal(t, 50*time.Second+time.Millisecond, retryAfter) // just into the next window
+
+	// 15s into the next window the previous one still weighs 3 × 0.75
+	*now = now.Add(65 * time.Second)
+	ok, _ = allow()
+	assert.True(t, ok)
+	ok, retryAfter = allow()
+	assert.False(t, ok)
+	assert.Equal(t, 5*time.Second+time.Millisecond, retryAfter) // until the weight is under 3 × 2/3
+
+	*now = now.Add(retryAfter)
+	ok, _ = allow()
+	assert.True(t, ok)
+}
+
+func TestMemoryRateLimitStore_SweepsIdleKeys(t *testing.T) {
+	s, now := fakeClockStore()
+	_, _, _ = s.Allow(context.Background(), "bucket", RateLimit{Limit: 10, Window: time.Second})
+	_, _, _ = s.Allow(context.Background(), "window", RateLimit{Strategy: SlidingWindow, Limit: 10, Window: time.Second})
+
+	*now = now.Add(2 * sweepInterval)
+	_, _, _ = s.Allow(context.Background(), "other", RateLimit{Limit: 10, Window: time.Second})
+	assert.Len(t, s.buckets, 1)
+	assert.Empty(t, s.windows)
+}
+
+func TestRateLimiter_UnaryInterceptor_ByTenant(t *testing.T) {
+	rm, _ := newRequestMetrics(prometheus.NewRegistry())
+	l := newRateLimiter(rm)
+	assert.NoError(t, l.add("/shop.Orders/*", RateLimit{Key: RateLimitByTenant, Limit: 1, Window: time.Minute}))
+	interceptor := l.unaryInterceptor()
+	call := func(ctx context.Context, method string) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, any) (any, error) { return nil, nil })
+		return err
+	}
+
+	assert.NoError(t, call(claimsCtx("u1", "acme", "user"), "/shop.Orders/List"))
+	assert.NoError(t, call(claimsCtx("u1", "globex", "user"), "/shop.Orders/List"))
+	assert.NoError(t, call(claimsCtx("u1", "acme", "user"), "/shop.Catalog/List")) // no rule
+
+	// same tenant, different user and method: shares the pattern's budget
+	err := call(claimsCtx("u2", "acme", "user"), "/shop.Orders/Get")
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		assert.IsType(t, &errdetails.RetryInfo{}, st.Details()[0])
+	}
+	assert.Equal(t, 1.0, promtest.ToFloat64(rm.rateLimited.WithLabelValues("grpc", "/shop.Orders/Get")))
+}
+
+func TestRateLimiter_AnonymousCallersByIP(t *testing.T) {
+	rm, _ := newRequestMetrics(prometheus.NewRegistry())
+	l := newRateLimiter(rm)
+	assert.NoError(t, l.add("/auth.Login/Login", RateLimit{Key: RateLimitByUser, Limit: 1, Window: time.Minute}))
+	interceptor := l.unaryInterceptor()
+	call := func(ip string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 5000}})
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/auth.Login/Login"},
+			func(context.Context, any) (any, error) { return nil, nil })
+		return err
+	}
+
+	assert.NoError(t, call("10.0.0.1"))
+	assert.NoError(t, call("10.0.0.2"))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("10.0.0.1")))
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Allow(context.Context, string, RateLimit) (bool, time.Duration, error) {
+	return false, 0, errors.New("redis: connection refused")
+}
+
+func TestRateLimiter_StoreErrorAllows(t *testing.T) {
+	logged := captureErrors(t)
+	rm, _ := newRequestMetrics(prometheus.NewRegistry())
+	l := newRateLimiter(rm)
+	l.store = failingRateLimitStore{}
+	assert.NoError(t, l.add("/svc/*", RateLimit{Limit: 1, Window: time.Minute}))
+
+	_, err := l.unaryInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/M"},
+		func(context.Context, any) (any, error) { return nil, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rate limit store failed, allowing request"}, *logged)
+}
+
+// limitedController has one rate-limited route.
+type limitedController struct{ limit RateLimit }
+
+func (c *limitedController) Routes() []Route {
+	return []Route{{Pattern: "/search", Method: http.MethodGet, RateLimit: c.limit,
+		Handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }}}
+}
+
+func TestBuilder_RouteRateLimit(t *testing.T) {
+	bs, err := New().
+		HTTPPort(":0").
+		AddRestController(func() *limitedController {
+			return &limitedController{limit: RateLimit{Key: RateLimitByIP, Limit: 1, Window: time.Minute}}
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		bs.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search", nil))
+		return rec
+	}
+	assert.Equal(t, http.StatusOK, get().Code)
+	rec := get()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+}
+
+func TestBuilder_RouteRateLimit_Invalid(t *testing.T) {
+	_, err := New().
+		HTTPPort(":0").
+		AddRestController(func() *limitedController {
+			return &limitedController{limit: RateLimit{Limit: 10}}
+		}).
+		Build()
+	assert.ErrorContains(t, err, "invalid rate limit for route /search")
+}
+
+func TestBuilder_RateLimit_Invalid(t *testing.T) {
+	mockLogger := withMockLogger(func() {
+		New().RateLimit("/svc/*", RateLimit{Limit: 0, Window: time.Second})
+	})
+	assert.True(t, mockLogger.isFatalCalled)
+	assert.Equal(t, "Invalid rate limit", mockLogger.fatalMsg)
+}
+
+func TestBuilder_RouteRateLimit_ByUserNeedsRequire(t *testing.T) {
+	_, err := New().
+		HTTPPort(":0").
+		AddRestController(func() *limitedController {
+			return &limitedController{limit: RateLimit{Key: RateLimitByUser, Limit: 1, Window: time.Minute}}
+		}).
+		Build()
+	assert.ErrorContains(t, err, "rate limit for route /search is keyed by tenant or user and needs Route.Require")
+}
+
+func TestRateLimit_Validate(t *testing.T) {
+	assert.EqualError(t, RateLimit{Window: time.Second}.validate(), "limit must be positive")
+	assert.EqualError(t, RateLimit{Limit: 1}.validate(), "window must be positive")
+	assert.EqualError(t, RateLimit{Limit: 1, Window: time.Second, Burst: -1}.validate(), "burst must not be negative")
+	assert.NoError(t, RateLimit{Limit: 1, Window: time.Second}.validate())
+}
+
+func TestBuilder_RouteRateLimit_NegativeBurst(t *testing.T) {
+	_, err := New().
+		HTTPPort(":0").
+		AddRestController(func() *limitedController {
+			return &limitedController{limit: RateLimit{Key: RateLimitByIP, Limit: 1, Window: time.Minute, Burst: -1}}
+		}).
+		Build()
+	assert.EqualError(t, err, "invalid rate limit for route /search: burst must not be negative")
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recoveryUnaryInterceptor turns a panic in an interceptor or handler further
+// down the chain into codes.Internal, counted in rm.
+func recoveryUnaryInterceptor(rm *requestMetrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverGRPC(ctx, rm, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func recoveryStreamInterceptor(rm *requestMetrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recoverGRPC(ss.Context(), rm, info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recoverGRPC(ctx context.Context, rm *requestMetrics, method string, p any) error {
+	rm.panicsRecovered.WithLabelValues("grpc", method).Inc()
+
+	fields := []zap.Field{
+		zap.String("grpc.method", method),
+		zap.String("panic", fmt.Sprint(p)),
+		zap.ByteString("stack", debug.Stack()),
+	}
+	for k, v := range grpc_ctxtags.Extract(ctx).Values() {
+		fields = append(fields, zap.Any(k, v))
+	}
+	logger.Error("Recovered from panic in gRPC handler", fields...)
+
+	return status.Error(codes.Internal, "internal server error")
+}
+
+// recoveryMiddleware answers 500 when next panics. pattern labels the metric,
+// so it must be a route pattern rather than the raw request path.
+func recoveryMiddleware(rm *requestMetrics, pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler { // deliberate abort, let net/http handle it
+				panic(p)
+			}
+
+			rm.panicsRecovered.WithLabelValues("http", pattern).Inc()
+			fields := []zap.Field{
+				zap.String("http.method", r.Method),
+				zap.String("http.path", r.URL.Path),
+				zap.String("http.pattern", pattern),
+				zap.String("peer.address", r.RemoteAddr),
+				zap.String("panic", fmt.Sprint(p)),
+				zap.ByteString("stack", debug.Stack()),
+			}
+			logger.Error("Recovered from panic in HTTP handler", append(fields, logger.ContextFields(r.Context())...)...)
+
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
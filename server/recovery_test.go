@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/prometheus/client_golang/prometheus"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// captureErrors records logger.Error calls for the duration of the test.
+func captureErrors(t *testing.T) *[]string {
+	t.Helper()
+	var msgs []string
+	orig := logger.Error
+	logger.Error = func(msg string, fields ...zap.Field) { msgs = append(msgs, msg) }
+	t.Cleanup(func() { logger.Error = orig })
+	return &msgs
+}
+
+func TestRecoveryUnaryInterceptor_ReturnsInternal(t *testing.T) {
+	logged := captureErrors(t)
+	method := "/test.Panic/Unary"
+	rm, _ := newRequestMetrics(prometheus.NewRegistry())
+
+	ctx := grpc_ctxtags.SetInContext(context.Background(), grpc_ctxtags.NewTags().Set("user", "u1"))
+	_, err := recoveryUnaryInterceptor(rm)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+		func(context.Context, any) (any, error) { panic("boom") })
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, []string{"Recovered from panic in gRPC handler"}, *logged)
+	assert.Equal(t, 1.0, promtest.ToFloat64(rm.panicsRecovered.WithLabelValues("grpc", method)))
+}
+
+func TestRecoveryStreamInterceptor_ReturnsInternal(t *testing.T) {
+	captureErrors(t)
+	ss := &scopeTestStream{ctx: context.Background()}
+	rm, _ := newRequestMetrics(prometheus.NewRegistry())
+
+	err := recoveryStreamInterceptor(rm)(nil, ss, &grpc.StreamServerInfo{FullMethod: "/test.Panic/Stream"},
+		func(any, grpc.ServerStream) error { panic("boom") })
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRecoveryMiddleware_Returns500(t *testing.T) {
+	logged := captureErrors(t)
+	rm, _ := newRequestMetrics(prometheus.NewRegistry())
+
+	h := recoveryMiddleware(rm, "/panic", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, []string{"Recovered from panic in HTTP handler"}, *logged)
+	assert.Equal(t, 1.0, promtest.ToFloat64(rm.panicsRecovered.WithLabelValues("http", "/panic")))
+}
+
+func TestRecoveryMiddleware_RepanicsAbortHandler(t *testing.T) {
+	h := recoveryMiddleware(&requestMetrics{}, "/abort", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
+
+// panicController panics in its only route.
+type panicController struct{}
+
+func (c *panicController) Routes() []Route {
+	return []Route{{Pattern: "/boom", Handler: func(http.ResponseWriter, *http.Request) { panic("boom") }}}
+}
+
+func TestBuilder_RestControllerPanic_Returns500(t *testing.T) {
+	captureErrors(t)
+	bs, err := New().
+		HTTPPort(":0").
+		AddRestController(func() *panicController { return &panicController{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	rec := httptest.NewRecorder()
+	bs.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
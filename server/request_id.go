@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/SaiNageswarS/go-api-boot/requestid"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// incomingRequestID accepts the caller's x-request-id when it is valid and
+// generates one otherwise.
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get(requestid.MetadataKey); len(ids) > 0 && requestid.Valid(ids[0]) {
+		return ids[0]
+	}
+	return requestid.New()
+}
+
+// withRequestID stores id in ctx and tags the call with it, so grpc_zap
+// logs carry it.
+func withRequestID(ctx context.Context, id string) context.Context {
+	grpc_ctxtags.Extract(ctx).Set("request_id", id)
+	return requestid.NewContext(ctx, id)
+}
+
+// echoRequestID reports the id in the response headers. Calls through the web
+// proxy skip it: requestIDMiddleware already set the HTTP header.
+func echoRequestID(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return len(md.Get(grpcWebHeader)) == 0
+}
+
+// requestIDUnaryInterceptor puts the request id in the context of every call
+// and echoes it in the response header metadata.
+func requestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := incomingRequestID(ctx)
+		if echoRequestID(ctx) {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id))
+		}
+		return handler(withRequestID(ctx, id), req)
+	}
+}
+
+func requestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		id := incomingRequestID(ss.Context())
+		if echoRequestID(ss.Context()) {
+			_ = ss.SetHeader(metadata.Pairs(requestid.MetadataKey, id))
+		}
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = withRequestID(ss.Context(), id)
+		return handler(srv, wrapped)
+	}
+}
+
+// requestIDMiddleware accepts or generates X-Request-Id, stores it in the
+// request context and echoes it. The request header is rewritten too, so the
+// web proxy hands the same id to the gRPC chain.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+			r.Header.Set(requestid.Header, id)
+		}
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+// ---- temporal ----------------------------------------------------------------
+
+type workflowRequestIDKey struct{}
+
+// WorkflowRequestID returns the request id that started the workflow, or ""
+// when there is none. Activities read theirs with requestid.FromContext.
+func WorkflowRequestID(ctx workflow.Context) string {
+	id, _ := ctx.Value(workflowRequestIDKey{}).(string)
+	return id
+}
+
+// RequestIDPropagator carries the request id from the context that starts a
+// workflow into the workflow, its activities and child workflows. Build adds
+// it to the worker's client; add it to the ContextPropagators of other
+// clients that start workflows.
+//
+// Example:
+//
+//	client.Dial(client.Options{
+//	    ContextPropagators: []workflow.ContextPropagator{server.RequestIDPropagator()},
+//	})
+func RequestIDPropagator() workflow.ContextPropagator { return requestIDPropagator{} }
+
+type requestIDPropagator struct{}
+
+func (requestIDPropagator) Inject(ctx context.Context, w workflow.HeaderWriter) error {
+	return injectRequestID(requestid.FromContext(ctx), w)
+}
+
+func (requestIDPropagator) InjectFromWorkflow(ctx workflow.Context, w workflow.HeaderWriter) error {
+	return injectRequestID(WorkflowRequestID(ctx), w)
+}
+
+func (requestIDPropagator) Extract(ctx context.Context, r workflow.HeaderReader) (context.Context, error) {
+	id, err := extractRequestID(r)
+	if err != nil || id == "" {
+		return ctx, err
+	}
+	return requestid.NewContext(ctx, id), nil
+}
+
+func (requestIDPropagator) ExtractToWorkflow(ctx workflow.Context, r workflow.HeaderReader) (workflow.Context, error) {
+	id, err := extractRequestID(r)
+	if err != nil || id == "" {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, workflowRequestIDKey{}, id), nil
+}
+
+func injectRequestID(id string, w workflow.HeaderWriter) error {
+	if id == "" {
+		return nil
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(id)
+	if err != nil {
+		return err
+	}
+	w.Set(requestid.MetadataKey, payload)
+	return nil
+}
+
+func extractRequestID(r workflow.HeaderReader) (string, error) {
+	payload, ok := r.Get(requestid.MetadataKey)
+	if !ok {
+		return "", nil
+	}
+	var id string
+	err := converter.GetDefaultDataConverter().FromPayload(payload, &id)
+	return id, err
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/requestid"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/stretchr/testify/assert"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDUnaryInterceptor(t *testing.T) {
+	interceptor := requestIDUnaryInterceptor()
+	call := func(md metadata.MD) (string, map[string]any) {
+		ctx := grpc_ctxtags.SetInContext(metadata.NewIncomingContext(context.Background(), md), grpc_ctxtags.NewTags())
+		var id string
+		_, _ = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/M"}, func(ctx context.Context, _ any) (any, error) {
+			id = requestid.FromContext(ctx)
+			return nil, nil
+		})
+		return id, grpc_ctxtags.Extract(ctx).Values()
+	}
+
+	id, tags := call(metadata.Pairs(requestid.MetadataKey, "req-1"))
+	assert.Equal(t, "req-1", id)
+	assert.Equal(t, "req-1", tags["request_id"])
+
+	id, _ = call(metadata.Pairs(requestid.MetadataKey, "bad id\n"))
+	assert.True(t, requestid.Valid(id))
+	assert.NotEqual(t, "bad id\n", id)
+
+	id, _ = call(metadata.MD{})
+	assert.True(t, requestid.Valid(id))
+}
+
+// headerStream records the header metadata sent on the stream.
+type headerStream struct {
+	scopeTestStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestRequestIDStreamInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "req-1"))
+	ss := &headerStream{scopeTestStream: scopeTestStream{ctx: ctx}}
+	var id string
+	err := requestIDStreamInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: "/svc/S"},
+		func(_ any, ss grpc.ServerStream) error {
+			id = requestid.FromContext(ss.Context())
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", id)
+	assert.Equal(t, []string{"req-1"}, ss.header.Get(requestid.MetadataKey))
+
+	// the web proxy already echoed the id as an HTTP header
+	webCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "req-2", grpcWebHeader, "1"))
+	web := &headerStream{scopeTestStream: scopeTestStream{ctx: webCtx}}
+	assert.NoError(t, requestIDStreamInterceptor()(nil, web, &grpc.StreamServerInfo{FullMethod: "/svc/S"},
+		func(any, grpc.ServerStream) error { return nil }))
+	assert.Empty(t, web.header)
+}
+
+// requestIDController reports the request id its handler sees.
+type requestIDController struct{ seen string }
+
+func (c *requestIDController) Routes() []Route {
+	return []Route{{Pattern: "/whoami", Method: http.MethodGet, Handler: func(w http.ResponseWriter, r *http.Request) {
+		c.seen = requestid.FromContext(r.Context())
+	}}}
+}
+
+func TestBuilder_RequestID_REST(t *testing.T) {
+	ctrl := &requestIDController{}
+	bs, err := New().
+		HTTPPort(":0").
+		AddRestController(func() *requestIDController { return ctrl }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	rec := httptest.NewRecorder()
+	bs.http.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, "req-1", ctrl.seen)
+	assert.Equal(t, "req-1", rec.Header().Get(requestid.Header))
+
+	rec = httptest.NewRecorder()
+	bs.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/whoami", nil))
+	assert.True(t, requestid.Valid(ctrl.seen))
+	assert.Equal(t, ctrl.seen, rec.Header().Get(requestid.Header))
+}
+
+type headerMap map[string]*commonpb.Payload
+
+func (h headerMap) Set(k string, v *commonpb.Payload) { h[k] = v }
+
+func requestIDWorkflow(ctx workflow.Context) ([]string, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+	var fromActivity string
+	err := workflow.ExecuteActivity(ctx, requestIDActivity).Get(ctx, &fromActivity)
+	return []string{WorkflowRequestID(ctx), fromActivity}, err
+}
+
+func requestIDActivity(ctx context.Context) (string, error) {
+	return requestid.FromContext(ctx), nil
+}
+
+func TestRequestIDPropagator_WorkflowAndActivity(t *testing.T) {
+	// what a client does when starting the workflow from a request
+	header := headerMap{}
+	assert.NoError(t, RequestIDPropagator().Inject(requestid.NewContext(context.Background(), "req-1"), header))
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.SetContextPropagators([]workflow.ContextPropagator{RequestIDPropagator()})
+	env.SetHeader(&commonpb.Header{Fields: header})
+	env.RegisterActivity(requestIDActivity)
+	env.ExecuteWorkflow(requestIDWorkflow)
+
+	assert.NoError(t, env.GetWorkflowError())
+	var got []string
+	assert.NoError(t, env.GetWorkflowResult(&got))
+	assert.Equal(t, []string{"req-1", "req-1"}, got)
+}
@@ -10,6 +10,22 @@ type RestController interface {
 	Routes() []Route
 }
 
+// Middleware wraps an HTTP handler, e.g. to log, authenticate or set headers.
+type Middleware func(http.Handler) http.Handler
+
+// HandlerFuncMiddleware adapts middleware written against http.HandlerFunc,
+// such as auth.VerifyTokenHttpMiddleware.
+func HandlerFuncMiddleware(mw func(http.HandlerFunc) http.HandlerFunc) Middleware {
+	return func(next http.Handler) http.Handler { return mw(next.ServeHTTP) }
+}
+
+// MiddlewareController is a RestController whose middleware wraps every one of
+// its routes, outside the routes' own middleware.
+type MiddlewareController interface {
+	RestController
+	Middleware() []Middleware
+}
+
 // Route defines a single HTTP route with its pattern and handler.
 type Route struct {
 	// Pattern is the URL pattern for the route (e.g., "/api/users", "/api/users/{id}").
@@ -21,6 +37,27 @@ type Route struct {
 
 	// Handler is the HTTP handler function for the route.
 	Handler http.HandlerFunc
+
+	// Require, if set, makes the route verify the bearer token and check the
+	// caller against the authorization policy.
+	Require Requirement
+
+	// RateLimit, if set, answers 429 with Retry-After once a caller exceeds it.
+	// Keying it by tenant or user needs Require, which verifies the token
+	// before the limit is checked.
+	RateLimit RateLimit
+
+	// Middleware wraps Handler, the first entry outermost. It runs after
+	// authorization and rate limiting, in the request scope.
+	Middleware []Middleware
+}
+
+// chain wraps h in mws, the first entry outermost.
+func chain(h http.Handler, mws []Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
 }
 
 // methodFilterHandler wraps a handler to only respond to a specific HTTP method.
@@ -36,3 +73,18 @@ func methodFilterHandler(method string, handler http.HandlerFunc) http.HandlerFu
 		handler(w, r)
 	}
 }
+
+// maxBodyMiddleware caps the request body at limit bytes; limit <= 0 disables it.
+func maxBodyMiddleware(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
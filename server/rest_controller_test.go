@@ -0,0 +1,99 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SaiNageswarS/go-api-boot/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordMiddleware appends name to calls and tags the response.
+func recordMiddleware(calls *[]string, name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls = append(*calls, name)
+			w.Header().Add("X-Middleware", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type middlewareController struct {
+	calls *[]string
+}
+
+func (c *middlewareController) Middleware() []Middleware {
+	return []Middleware{recordMiddleware(c.calls, "controller")}
+}
+
+func (c *middlewareController) Routes() []Route {
+	return []Route{
+		{Pattern: "/orders", Method: http.MethodGet, Middleware: []Middleware{recordMiddleware(c.calls, "route")},
+			Handler: func(w http.ResponseWriter, r *http.Request) { *c.calls = append(*c.calls, "handler") }},
+		{Pattern: "/secure", Method: http.MethodGet, Middleware: []Middleware{HandlerFuncMiddleware(auth.VerifyTokenHttpMiddleware)},
+			Handler: func(w http.ResponseWriter, r *http.Request) { *c.calls = append(*c.calls, "secure") }},
+	}
+}
+
+func TestBuilder_Middleware_Order(t *testing.T) {
+	var calls []string
+	static := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(static, "app.js"), []byte("ok"), 0o644))
+
+	bs, err := New().
+		HTTPPort(":0").
+		StaticDir(static).
+		Use(recordMiddleware(&calls, "global-1"), recordMiddleware(&calls, "global-2")).
+		AddRestController(func() *middlewareController { return &middlewareController{calls: &calls} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+	serve := func(path string) *httptest.ResponseRecorder {
+		calls = nil
+		rec := httptest.NewRecorder()
+		bs.http.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	serve("/orders")
+	assert.Equal(t, []string{"global-1", "global-2", "controller", "route", "handler"}, calls)
+
+	rec := serve("/secure")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, []string{"global-1", "global-2", "controller"}, calls)
+
+	rec = serve("/static/app.js")
+	assert.Equal(t, "ok", rec.Body.String())
+	assert.Equal(t, []string{"global-1", "global-2"}, calls)
+
+	serve("/api/grpc.health.v1.Health/Check")
+	assert.Equal(t, []string{"global-1", "global-2"}, calls)
+
+	rec = serve("/health")
+	assert.Empty(t, calls)
+	assert.Empty(t, rec.Header().Values("X-Middleware"))
+}
+
+type nilMiddlewareController struct{}
+
+func (nilMiddlewareController) Routes() []Route {
+	return []Route{{Pattern: "/x", Middleware: []Middleware{nil}, Handler: func(http.ResponseWriter, *http.Request) {}}}
+}
+
+func TestBuilder_Middleware_Nil(t *testing.T) {
+	_, err := New().
+		HTTPPort(":0").
+		AddRestController(func() nilMiddlewareController { return nilMiddlewareController{} }).
+		Build()
+	assert.ErrorContains(t, err, "nil middleware for route /x")
+
+	mockLogger := withMockLogger(func() { New().Use(nil) })
+	assert.True(t, mockLogger.isFatalCalled)
+	assert.Equal(t, "middleware must not be nil", mockLogger.fatalMsg)
+}
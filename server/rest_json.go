@@ -0,0 +1,398 @@
+package server
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Validator is implemented by request types that check themselves once bound.
+// Protobuf messages generated with protoc-gen-validate satisfy it.
+type Validator interface {
+	Validate() error
+}
+
+// JSON adapts fn into a Route handler. The request is bound into Req in three
+// steps, later ones overriding earlier ones:
+//
+//  1. the JSON body, if any;
+//  2. query parameters, into fields tagged `query:"name"`;
+//  3. path wildcards of the route pattern, into fields tagged `path:"name"`.
+//
+// Req (or *Req) may implement Validator. The response is written as JSON with
+// 200. Errors are written with WriteJSONError, so handlers return gRPC status
+// errors (status.Error(codes.NotFound, ...)) exactly as services do.
+//
+// Example:
+//
+//	type getUserRequest struct {
+//	    ID     string   `path:"id"`
+//	    Fields []string `query:"fields"`
+//	}
+//
+//	server.Route{Pattern: "/users/{id}", Method: http.MethodGet,
+//	    Handler: server.JSON(func(ctx context.Context, req getUserRequest) (*User, error) { ... })}
+func JSON[Req, Resp any](fn func(context.Context, Req) (Resp, error)) http.HandlerFunc {
+	reqType := reflect.TypeFor[Req]()
+	fields, err := bindFieldsOf(reqType)
+	if err != nil {
+		logger.Fatal("Invalid JSON handler request type", zap.String("type", reqType.String()), zap.Error(err))
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if reqType.Kind() == reflect.Pointer {
+			reflect.ValueOf(&req).Elem().Set(reflect.New(reqType.Elem()))
+		}
+		if httpStatus, st := bindRequest(r, &req, fields); st != nil {
+			writeJSONError(w, r, httpStatus, st)
+			return
+		}
+		if err := validateRequest(&req); err != nil {
+			WriteJSONError(w, r, err)
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			WriteJSONError(w, r, err)
+			return
+		}
+		body, err := json.Marshal(resp)
+		if err != nil {
+			WriteJSONError(w, r, fmt.Errorf("encoding response: %w", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}
+}
+
+// bindField is a struct field filled from the path or query string.
+type bindField struct {
+	index  []int
+	source string // "path" or "query"
+	name   string
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// bindFieldsOf lists the tagged fields of t (a struct or pointer to one),
+// including those of embedded structs, and rejects types it cannot parse.
+func bindFieldsOf(t reflect.Type) ([]bindField, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var fields []bindField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			nested, err := bindFieldsOf(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range nested {
+				n.index = append([]int{i}, n.index...)
+				fields = append(fields, n)
+			}
+			continue
+		}
+		for _, source := range []string{"path", "query"} {
+			name, ok := f.Tag.Lookup(source)
+			if !ok {
+				continue
+			}
+			if !f.IsExported() {
+				return nil, fmt.Errorf("field %s has a %s tag but is not exported", f.Name, source)
+			}
+			if !bindable(f.Type, source == "query") {
+				return nil, fmt.Errorf("field %s: cannot bind %s parameters into %s", f.Name, source, f.Type)
+			}
+			fields = append(fields, bindField{index: []int{i}, source: source, name: name})
+		}
+	}
+	return fields, nil
+}
+
+// bindable reports whether a parameter can be parsed into t; query
+// parameters may repeat, so they can also fill slices.
+func bindable(t reflect.Type, allowSlice bool) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Pointer:
+		return bindable(t.Elem(), false)
+	case reflect.Slice:
+		return allowSlice && bindable(t.Elem(), false)
+	}
+	return false
+}
+
+// bindRequest decodes r into req. On failure it returns the HTTP status and
+// the error to report.
+func bindRequest(r *http.Request, req any, fields []bindField) (int, *status.Status) {
+	if httpStatus, st := decodeBody(r, req); st != nil {
+		return httpStatus, st
+	}
+
+	v := reflect.ValueOf(req).Elem()
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	query := r.URL.Query()
+	for _, source := range []string{"query", "path"} {
+		for _, f := range fields {
+			if f.source != source {
+				continue
+			}
+			var values []string
+			if source == "path" {
+				if pv := r.PathValue(f.name); pv != "" {
+					values = []string{pv}
+				}
+			} else {
+				values = query[f.name]
+			}
+			if len(values) == 0 {
+				continue
+			}
+			if err := setField(v.FieldByIndex(f.index), values); err != nil {
+				return http.StatusBadRequest, badRequest(f.name, fmt.Sprintf("invalid %s parameter %q: %v", source, f.name, err))
+			}
+		}
+	}
+	return 0, nil
+}
+
+func decodeBody(r *http.Request, req any) (int, *status.Status) {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return 0, nil
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mt != "application/json" && !strings.HasSuffix(mt, "+json")) {
+			return http.StatusUnsupportedMediaType, status.Newf(codes.InvalidArgument, "unsupported content type %q", ct)
+		}
+	}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	var maxBytes *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil, errors.Is(err, io.EOF):
+		return 0, nil
+	case errors.As(err, &maxBytes):
+		return http.StatusRequestEntityTooLarge, status.Newf(codes.InvalidArgument, "request body exceeds %d bytes", maxBytes.Limit)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return http.StatusBadRequest, badRequest(typeErr.Field, fmt.Sprintf("invalid value for %q: expected %s", typeErr.Field, typeErr.Type))
+	default:
+		return http.StatusBadRequest, status.Newf(codes.InvalidArgument, "invalid JSON body: %v", err)
+	}
+}
+
+func setField(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && !v.Addr().Type().Implements(textUnmarshalerType) {
+		s := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, raw := range values {
+			if err := setValue(s.Index(i), raw); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return setValue(v, values[len(values)-1])
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+func setValue(v reflect.Value, raw string) error {
+	if tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(raw))
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		if err := setValue(p.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("expected a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return errors.New("expected a duration such as 1m30s")
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected a number")
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
+
+func badRequest(field, msg string) *status.Status {
+	st := status.New(codes.InvalidArgument, msg)
+	if withDetail, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: msg}},
+	}); err == nil {
+		st = withDetail
+	}
+	return st
+}
+
+// validateRequest runs Validate on the request or a pointer to it; plain
+// errors become codes.InvalidArgument.
+func validateRequest(req any) error {
+	v, ok := reflect.ValueOf(req).Elem().Interface().(Validator)
+	if !ok {
+		v, ok = req.(Validator)
+	}
+	if !ok {
+		return nil
+	}
+	err := v.Validate()
+	if err == nil {
+		return nil
+	}
+	if _, isStatus := status.FromError(err); isStatus {
+		return err
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// errorEnvelope is the JSON error body, shaped like the errors of Google's
+// JSON APIs:
+//
+//	{"error": {"code": 404, "status": "NOT_FOUND", "message": "user not found"}}
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    int               `json:"code"`
+	Status  string            `json:"status"`
+	Message string            `json:"message"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// WriteJSONError writes err as the JSON error envelope used by JSON handlers.
+// gRPC status errors keep their code, message and details and map to the
+// matching HTTP status; context errors map to 499 / 504. Any other error is
+// logged and reported as a 500 without its message.
+func WriteJSONError(w http.ResponseWriter, r *http.Request, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		if ctxErr := status.FromContextError(err); ctxErr.Code() != codes.Unknown {
+			st = ctxErr
+		} else {
+			logger.Error("JSON handler failed",
+				append([]zap.Field{zap.String("method", r.Method), zap.String("path", r.URL.Path), zap.Error(err)},
+					logger.ContextFields(r.Context())...)...)
+			st = status.New(codes.Internal, "Internal Server Error")
+		}
+	}
+	writeJSONError(w, r, httpStatusFromCode(st.Code()), st)
+}
+
+func writeJSONError(w http.ResponseWriter, r *http.Request, httpStatus int, st *status.Status) {
+	body := errorBody{
+		Code:    httpStatus,
+		Status:  code.Code(st.Code()).String(),
+		Message: st.Message(),
+	}
+	for _, d := range st.Proto().GetDetails() {
+		if raw, err := protojson.Marshal(d); err == nil {
+			body.Details = append(body.Details, raw)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(httpStatus)
+	if err := json.NewEncoder(w).Encode(errorEnvelope{Error: body}); err != nil {
+		logger.Error("Failed to write JSON error", append([]zap.Field{zap.Error(err)}, logger.ContextFields(r.Context())...)...)
+	}
+}
+
+// httpStatusFromCode maps gRPC codes to HTTP statuses as grpc-gateway does.
+func httpStatusFromCode(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default: // Unknown, Internal, DataLoss
+		return http.StatusInternalServerError
+	}
+}
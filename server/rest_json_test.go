@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type paging struct {
+	Limit int `query:"limit"`
+}
+
+type updateItemRequest struct {
+	paging
+	ID      string        `path:"id"`
+	Tags    []string      `query:"tag"`
+	Timeout time.Duration `query:"timeout"`
+	Name    string        `json:"name"`
+	Qty     int           `json:"qty"`
+}
+
+func (r updateItemRequest) Validate() error {
+	if r.Qty < 0 {
+		return errors.New("qty must not be negative")
+	}
+	return nil
+}
+
+type item struct {
+	ID   string   `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// serveJSON routes one request through a mux, so path values are set.
+func serveJSON(pattern string, h http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.Handle(pattern, h)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeEnvelope(t *testing.T, rec *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+	var env errorEnvelope
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	return env.Error
+}
+
+func TestJSON_BindsPathQueryAndBody(t *testing.T) {
+	var got updateItemRequest
+	h := JSON(func(_ context.Context, req updateItemRequest) (item, error) {
+		got = req
+		return item{ID: req.ID, Name: req.Name, Tags: req.Tags}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/items/42?tag=a&tag=b&limit=10&timeout=1m",
+		strings.NewReader(`{"name":"widget","qty":3}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serveJSON("PUT /items/{id}", h, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id":"42","name":"widget","tags":["a","b"]}`, rec.Body.String())
+	assert.Equal(t, updateItemRequest{paging: paging{Limit: 10}, ID: "42", Tags: []string{"a", "b"},
+		Timeout: time.Minute, Name: "widget", Qty: 3}, got)
+}
+
+func TestJSON_PointerRequest(t *testing.T) {
+	h := JSON(func(_ context.Context, req *updateItemRequest) (*item, error) {
+		return &item{ID: req.ID}, nil
+	})
+	rec := serveJSON("GET /items/{id}", h, httptest.NewRequest(http.MethodGet, "/items/7", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":"7","name":""}`, rec.Body.String())
+}
+
+func TestJSON_BindingErrors(t *testing.T) {
+	called := false
+	h := JSON(func(context.Context, updateItemRequest) (item, error) {
+		called = true
+		return item{}, nil
+	})
+
+	rec := serveJSON("PUT /items/{id}", h, httptest.NewRequest(http.MethodPut, "/items/1?limit=ten", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeEnvelope(t, rec)
+	assert.Equal(t, "INVALID_ARGUMENT", body.Status)
+	assert.Equal(t, `invalid query parameter "limit": expected an integer`, body.Message)
+	if assert.Len(t, body.Details, 1) {
+		assert.Contains(t, string(body.Details[0]), `"field":"limit"`)
+	}
+
+	rec = serveJSON("PUT /items/{id}", h, httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{"qty":"many"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, decodeEnvelope(t, rec).Message, `invalid value for "qty"`)
+
+	rec = serveJSON("PUT /items/{id}", h, httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{"name":`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`name=x`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = serveJSON("PUT /items/{id}", h, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = serveJSON("PUT /items/{id}", h, httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{"qty":-1}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "qty must not be negative", decodeEnvelope(t, rec).Message)
+
+	assert.False(t, called)
+}
+
+func TestJSON_BodyTooLarge(t *testing.T) {
+	h := maxBodyMiddleware(8, JSON(func(context.Context, updateItemRequest) (item, error) { return item{}, nil }))
+	req := httptest.NewRequest(http.MethodPut, "/items/1", strings.NewReader(`{"name":"much too long"}`))
+	req.ContentLength = -1 // chunked, so only the reader enforces the limit
+	rec := serveJSON("PUT /items/{id}", h.ServeHTTP, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestJSON_MapsErrors(t *testing.T) {
+	logged := captureErrors(t)
+	cases := []struct {
+		err     error
+		code    int
+		status  string
+		message string
+	}{
+		{status.Error(codes.NotFound, "item 1 not found"), http.StatusNotFound, "NOT_FOUND", "item 1 not found"},
+		{status.Error(codes.PermissionDenied, "nope"), http.StatusForbidden, "PERMISSION_DENIED", "nope"},
+		{status.Error(codes.AlreadyExists, "dup"), http.StatusConflict, "ALREADY_EXISTS", "dup"},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, "DEADLINE_EXCEEDED", context.DeadlineExceeded.Error()},
+		{errors.New("mongo: connection reset"), http.StatusInternalServerError, "INTERNAL", "Internal Server Error"},
+	}
+	for _, c := range cases {
+		h := JSON(func(context.Context, struct{}) (item, error) { return item{}, c.err })
+		rec := serveJSON("/items", h, httptest.NewRequest(http.MethodGet, "/items", nil))
+		assert.Equal(t, c.code, rec.Code)
+		body := decodeEnvelope(t, rec)
+		assert.Equal(t, c.code, body.Code)
+		assert.Equal(t, c.status, body.Status)
+		assert.Equal(t, c.message, body.Message)
+	}
+	assert.Equal(t, []string{"JSON handler failed"}, *logged, "only unexpected errors are logged")
+}
+
+func TestJSON_InvalidRequestType(t *testing.T) {
+	type badRequest struct {
+		Filter map[string]string `query:"filter"`
+	}
+	mockLogger := withMockLogger(func() {
+		JSON(func(context.Context, badRequest) (item, error) { return item{}, nil })
+	})
+	assert.True(t, mockLogger.isFatalCalled)
+	assert.Equal(t, "Invalid JSON handler request type", mockLogger.fatalMsg)
+}
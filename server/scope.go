@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+)
+
+// Lifetime controls how often a ProvideFunc provider is invoked.
+type Lifetime int
+
+const (
+	// Singleton providers run once; the value is shared by every consumer.
+	Singleton Lifetime = iota
+	// Transient providers run for every consumer that requests the type.
+	Transient
+	// Scoped providers run at most once per gRPC / HTTP request. Their values
+	// are not injectable into factories; read them with server.Resolve(ctx).
+	Scoped
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	default:
+		return "singleton"
+	}
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+type scopeCtxKey struct{}
+
+// requestScope memoises Scoped values for a single request. Scoped providers
+// may depend on singletons, transients, other scoped values and on
+// context.Context, which resolves to the context passed to Resolve.
+type requestScope struct {
+	ctn      *container
+	mu       sync.Mutex
+	values   map[reflect.Type]reflect.Value
+	cleanups []func()
+}
+
+func newRequestScope(ctn *container) *requestScope {
+	return &requestScope{ctn: ctn}
+}
+
+func (s *requestScope) resolve(ctx context.Context, t reflect.Type) (reflect.Value, error) {
+	if t == contextType {
+		return reflect.ValueOf(&ctx).Elem(), nil
+	}
+
+	p, ok := s.ctn.providers[t]
+	lifetime := s.ctn.lifetimes[t]
+	if !ok || lifetime == Singleton {
+		s.ctn.mu.Lock()
+		defer s.ctn.mu.Unlock()
+		resolved := len(s.ctn.order)
+		v, err := s.ctn.resolve(t)
+		if err == nil && s.ctn.lifecycle != nil {
+			// singletons first built here still get their hooks and cleanups
+			err = s.ctn.lifecycle.add(context.WithoutCancel(ctx), s.ctn.order[resolved:])
+		}
+		return v, err
+	}
+
+	if lifetime == Scoped {
+		if v, ok := s.values[t]; ok {
+			return v, nil
+		}
+	}
+
+	v, cleanup, err := callProvider(t, p, func(dep reflect.Type) (reflect.Value, error) {
+		return s.resolve(ctx, dep)
+	})
+	if err != nil {
+		return v, err
+	}
+	if cleanup != nil {
+		s.cleanups = append(s.cleanups, cleanup)
+	}
+	if lifetime == Scoped {
+		if s.values == nil {
+			s.values = map[reflect.Type]reflect.Value{}
+		}
+		s.values[t] = v
+	}
+	return v, nil
+}
+
+// close runs cleanups of scoped / transient values in reverse order.
+func (s *requestScope) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.cleanups) - 1; i >= 0; i-- {
+		s.cleanups[i]()
+	}
+	s.cleanups = nil
+}
+
+// Resolve returns the value of type T for the request carried by ctx.
+// Request-scoped values are created lazily on first access and shared for the
+// rest of the request; singletons and transients are resolved as usual.
+//
+// Example:
+//
+//	func (s *ProfileService) Get(ctx context.Context, req *pb.GetRequest) (*pb.Profile, error) {
+//	    profiles, err := server.Resolve[odm.OdmCollectionInterface[db.ProfileModel]](ctx)
+//	    ...
+//	}
+func Resolve[T any](ctx context.Context) (T, error) {
+	var out T
+	s, ok := ctx.Value(scopeCtxKey{}).(*requestScope)
+	if !ok {
+		return out, errors.New("no request scope in context")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := reflect.ValueOf(&out).Elem()
+	v, err := s.resolve(ctx, target.Type())
+	if err != nil {
+		return out, err
+	}
+	if !v.Type().AssignableTo(target.Type()) {
+		return out, fmt.Errorf("resolved %v is not assignable to %v", v.Type(), target.Type())
+	}
+	target.Set(v)
+	return out, nil
+}
+
+// withRequestScope opens a scope for the duration of fn.
+func withRequestScope(ctx context.Context, ctn *container, fn func(context.Context) error) error {
+	s := newRequestScope(ctn)
+	defer s.close()
+	return fn(context.WithValue(ctx, scopeCtxKey{}, s))
+}
+
+func scopeUnaryInterceptor(ctn *container) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		err = withRequestScope(ctx, ctn, func(ctx context.Context) error {
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+func scopeStreamInterceptor(ctn *container) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return withRequestScope(ss.Context(), ctn, func(ctx context.Context) error {
+			wrapped := grpc_middleware.WrapServerStream(ss)
+			wrapped.WrappedContext = ctx
+			return handler(srv, wrapped)
+		})
+	}
+}
+
+// scopeMiddleware opens a request scope for REST handlers.
+func scopeMiddleware(ctn *container, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = withRequestScope(r.Context(), ctn, func(ctx context.Context) error {
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return nil
+		})
+	})
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type tenantKey struct{}
+
+// unitOfWork is a request-scoped test type.
+type unitOfWork struct {
+	tenant string
+	dep    *dep
+}
+
+func TestBuilder_ProvideTransient_NewInstancePerConsumer(t *testing.T) {
+	p := &depProvider{}
+	spy1, spy2 := &regSpy{}, &regSpy{}
+
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideTransient(p.provide).
+		RegisterService(spy1.fn, func(d *dep) *svc { return &svc{d: d} }).
+		RegisterService(spy2.fn, func(d *dep) *svc { return &svc{d: d} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	assert.Equal(t, 2, p.called)
+	assert.NotSame(t, spy1.gotSrv.(*svc).d, spy2.gotSrv.(*svc).d)
+}
+
+func TestBuilder_ProvideScoped_NotInjectableIntoFactories(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		Provide(&dep{}).
+		ProvideScoped(func(d *dep) *unitOfWork { return &unitOfWork{dep: d} }).
+		RegisterService((&regSpy{}).fn, func(u *unitOfWork) *svc { return &svc{} }).
+		Build()
+
+	assert.ErrorContains(t, err, "request-scoped *server.unitOfWork cannot be injected into service *server.svc")
+}
+
+func TestBuilder_ProvideScoped_ValidatesScopedDependencies(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideScoped(func(ctx context.Context, d *dep) *unitOfWork { return &unitOfWork{dep: d} }).
+		Build()
+
+	assert.ErrorContains(t, err, "request scope DI failed")
+	assert.ErrorContains(t, err, "no provider for *server.dep (requested by provider of *server.unitOfWork)")
+}
+
+func scopedContainer(created *int, cleaned *int) *container {
+	c := makeContainer()
+	d := &dep{id: 5}
+	c.singletons[reflect.TypeOf(d)] = reflect.ValueOf(d)
+	c.providers[reflect.TypeOf(&unitOfWork{})] = reflect.ValueOf(func(ctx context.Context, d *dep) (*unitOfWork, func(), error) {
+		*created++
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return &unitOfWork{tenant: tenant, dep: d}, func() { *cleaned++ }, nil
+	})
+	c.lifetimes[reflect.TypeOf(&unitOfWork{})] = Scoped
+	return c
+}
+
+func TestScopeUnaryInterceptor_MemoisesPerRequest(t *testing.T) {
+	var created, cleaned int
+	c := scopedContainer(&created, &cleaned)
+	interceptor := scopeUnaryInterceptor(c)
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		u1, err := Resolve[*unitOfWork](ctx)
+		if err != nil {
+			return nil, err
+		}
+		u2, _ := Resolve[*unitOfWork](ctx)
+		assert.Same(t, u1, u2, "scoped value must be shared within a request")
+		assert.Equal(t, created-1, cleaned, "cleanup must not run before the request completes")
+		return u1, nil
+	}
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	first, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, err)
+	second, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, err)
+
+	assert.NotSame(t, first, second, "each request gets its own scoped value")
+	assert.Equal(t, "acme", first.(*unitOfWork).tenant)
+	assert.Equal(t, 5, first.(*unitOfWork).dep.id)
+	assert.Equal(t, 2, created)
+	assert.Equal(t, 2, cleaned)
+}
+
+// scopeTestStream is a minimal grpc.ServerStream carrying a context.
+type scopeTestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *scopeTestStream) Context() context.Context { return s.ctx }
+
+func TestScopeStreamInterceptor_ExposesScope(t *testing.T) {
+	var created, cleaned int
+	c := scopedContainer(&created, &cleaned)
+	interceptor := scopeStreamInterceptor(c)
+
+	err := interceptor(nil, &scopeTestStream{ctx: context.Background()}, &grpc.StreamServerInfo{},
+		func(srv any, ss grpc.ServerStream) error {
+			_, err := Resolve[*unitOfWork](ss.Context())
+			return err
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 1, cleaned)
+}
+
+func TestResolve_WithoutScope_ReturnsError(t *testing.T) {
+	_, err := Resolve[*unitOfWork](context.Background())
+	assert.Error(t, err)
+}
+
+// scopedRestController reads a request-scoped value inside its handler.
+type scopedRestController struct{}
+
+func (c *scopedRestController) Routes() []Route {
+	return []Route{{
+		Pattern: "/scoped",
+		Method:  "GET",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			u, err := Resolve[*unitOfWork](r.Context())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(u.tenant))
+		},
+	}}
+}
+
+func TestBuilder_ProvideScoped_RestController(t *testing.T) {
+	boot, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideScoped(func(ctx context.Context) *unitOfWork {
+			return &unitOfWork{tenant: "from-" + ctx.Value(tenantKey{}).(string)}
+		}).
+		AddRestController(func() *scopedRestController { return &scopedRestController{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer boot.Shutdown(context.Background())
+
+	req := httptest.NewRequest("GET", "/scoped", nil)
+	req = req.WithContext(context.WithValue(req.Context(), tenantKey{}, "http"))
+	rec := httptest.NewRecorder()
+	boot.http.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "from-http", rec.Body.String())
+}
+
+// lateReport is scoped and depends on a singleton that nothing else needs, so
+// the singleton is first built inside a request.
+type lateReport struct{ c *hookComp }
+
+func TestBuilder_SingletonFirstResolvedInScope_RunsHooks(t *testing.T) {
+	rec := &hookRecorder{}
+	boot, err := New().
+		HTTPPort(":0").
+		ProvideFunc(func() (*hookComp, func(), error) {
+			return &hookComp{name: "late", rec: rec}, func() { rec.add("cleanup:late") }, nil
+		}).
+		ProvideScoped(func(c *hookComp) *lateReport { return &lateReport{c: c} }).
+		AddRestController(func() *lateController { return &lateController{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- boot.Serve(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, rec.snapshot(), "nothing needs the singleton at Build")
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		boot.http.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "/late", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+	assert.Equal(t, []string{"start:late"}, rec.snapshot())
+
+	cancel()
+	<-done
+	assert.Equal(t, []string{"start:late", "stop:late", "cleanup:late"}, rec.snapshot())
+}
+
+type lateController struct{}
+
+func (c *lateController) Routes() []Route {
+	return []Route{{Pattern: "/late", Method: "GET", Handler: func(w http.ResponseWriter, r *http.Request) {
+		if _, err := Resolve[*lateReport](r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}}}
+}
@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/SaiNageswarS/go-api-boot/logger"
@@ -22,61 +25,163 @@ type BootServer struct {
 	sslProvider    SSLProvider
 	temporalWorker worker.Worker
 	temporalClient client.Client
+	lifecycle      *lifecycle
+	graph          *Graph
+	health         *health
+
+	shutdownTimeout  time.Duration
+	grpcDrainTimeout time.Duration
+
+	// Serve and Shutdown both stop the servers; only the first does
+	stopServersOnce sync.Once
+	stopServersErr  error
 }
 
+// Graph returns the dependency graph resolved by Build.
+func (s *BootServer) Graph() *Graph { return s.graph }
+
 // Serve blocks until context is cancelled or a listen error occurs.
+// It runs whichever of the gRPC listener, HTTP listener and Temporal worker
+// were configured.
+// Components implementing Starter are started in dependency order before the
+// listeners accept traffic; Stopper components are stopped in reverse order
+// after the servers have shut down. If a Start hook fails, Serve closes the
+// listeners, stops what was started and returns the error.
 func (s *BootServer) Serve(ctx context.Context) error {
+	if s.lifecycle != nil {
+		if err := s.lifecycle.start(ctx); err != nil {
+			// nothing will serve the listeners; free the ports and stop
+			// whatever was started before returning
+			s.closeListeners()
+			if stopErr := s.stopComponents(context.WithoutCancel(ctx)); stopErr != nil {
+				logger.Error("Lifecycle stop failed", zap.Error(stopErr))
+			}
+			return fmt.Errorf("lifecycle start failed: %w", err)
+		}
+	}
+
 	grp, ctx := errgroup.WithContext(ctx)
 
-	// Start gRPC server
-	grp.Go(func() error {
-		logger.Info("Starting gRPC server at", zap.String("port", s.lnGrpc.Addr().String()))
-		return s.grpc.Serve(s.lnGrpc)
-	})
+	// Start gRPC server if configured
+	if s.lnGrpc != nil {
+		grp.Go(func() error {
+			logger.Info("Starting gRPC server at", zap.String("port", s.lnGrpc.Addr().String()))
+			return s.grpc.Serve(s.lnGrpc)
+		})
+	}
 
-	// Start HTTP server
-	grp.Go(func() error {
-		if s.sslProvider != nil {
-			// run ACME helper concurrently
-			if err := s.sslProvider.Run(ctx); err != nil && ctx.Err() == nil {
-				return err
+	// Start HTTP server if configured
+	if s.lnHTTP != nil {
+		grp.Go(func() error {
+			if s.sslProvider != nil {
+				// run ACME helper concurrently
+				if err := s.sslProvider.Run(ctx); err != nil && ctx.Err() == nil {
+					return err
+				}
+			}
+			// choose ServeTLS vs Serve
+			if s.sslProvider != nil {
+				logger.Info("Starting https server at", zap.String("port", s.lnHTTP.Addr().String()))
+				return s.http.ServeTLS(s.lnHTTP, "", "")
 			}
-		}
-		// choose ServeTLS vs Serve
-		if s.sslProvider != nil {
-			logger.Info("Starting https server at", zap.String("port", s.lnHTTP.Addr().String()))
-			return s.http.ServeTLS(s.lnHTTP, "", "")
-		}
 
-		logger.Info("Starting http server at", zap.String("port", s.lnHTTP.Addr().String()))
-		return s.http.Serve(s.lnHTTP)
-	})
+			logger.Info("Starting http server at", zap.String("port", s.lnHTTP.Addr().String()))
+			return s.http.Serve(s.lnHTTP)
+		})
+	}
 
 	// Start Temporal worker if configured
 	if s.temporalWorker != nil {
 		grp.Go(func() error {
 			logger.Info("Starting Temporal worker...")
-			return s.temporalWorker.Run(worker.InterruptCh())
+			// the worker stops with ctx, like the servers
+			stop := make(chan interface{})
+			go func() {
+				<-ctx.Done()
+				close(stop)
+			}()
+			return s.temporalWorker.Run(stop)
 		})
 	}
 
 	// Wait for ctx cancellation
 	<-ctx.Done()
 
-	shutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
+	_ = s.stopServers(shutCtx)
 
-	s.grpc.GracefulStop()
-	_ = s.http.Shutdown(shutCtx)
-	if s.temporalClient != nil {
-		s.temporalClient.Close()
+	err := grp.Wait()
+	if stopErr := s.stopComponents(context.Background()); stopErr != nil {
+		logger.Error("Lifecycle stop failed", zap.Error(stopErr))
+		err = errors.Join(err, stopErr)
 	}
-
-	return grp.Wait()
+	return err
 }
 
-// Shutdown is rarely needed (Serve handles it), but exposed for tests.
+// Shutdown is rarely needed (Serve handles it), but exposed for tests and
+// for servers that were built but never served. Like Serve, it stops the
+// servers and then runs Stop hooks and provider cleanups; each step runs once
+// however Serve and Shutdown are combined.
 func (s *BootServer) Shutdown(ctx context.Context) error {
-	s.grpc.GracefulStop()
-	return s.http.Shutdown(ctx)
+	return errors.Join(s.stopServers(ctx), s.stopComponents(ctx))
+}
+
+// stopServers fails readiness, so load balancers stop routing new traffic,
+// then stops the gRPC and HTTP servers and the Temporal client.
+func (s *BootServer) stopServers(ctx context.Context) error {
+	s.stopServersOnce.Do(func() {
+		if s.health != nil {
+			s.health.draining.Store(true)
+		}
+		s.stopGRPC()
+		if s.http != nil {
+			s.stopServersErr = s.http.Shutdown(ctx)
+		}
+		if s.temporalClient != nil {
+			s.temporalClient.Close()
+		}
+	})
+	return s.stopServersErr
+}
+
+func (s *BootServer) closeListeners() {
+	for _, ln := range []net.Listener{s.lnGrpc, s.lnHTTP} {
+		if ln != nil {
+			_ = ln.Close()
+		}
+	}
+}
+
+func (s *BootServer) stopComponents(ctx context.Context) error {
+	if s.lifecycle == nil {
+		return nil
+	}
+	return s.lifecycle.stop(ctx)
+}
+
+// stopGRPC stops the gRPC server gracefully, closing RPCs and streams still
+// running after the drain timeout.
+func (s *BootServer) stopGRPC() {
+	if s.grpc == nil {
+		return
+	}
+	if s.grpcDrainTimeout <= 0 {
+		s.grpc.GracefulStop()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.grpcDrainTimeout):
+		logger.Error("gRPC drain timed out, closing remaining streams", zap.Duration("timeout", s.grpcDrainTimeout))
+		s.grpc.Stop()
+		<-done
+	}
 }
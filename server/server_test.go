@@ -3,7 +3,11 @@ package server
 import (
 	"context"
 	"errors"
+	"io"
+	"net"
+	"net/http"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -12,6 +16,9 @@ import (
 	"github.com/nexus-rpc/sdk-go/nexus"
 	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/workflow"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // -----------------------------------------------------------------------------
@@ -44,21 +51,12 @@ func TestBootServer_Shutdown_UnblocksServe(t *testing.T) {
 }
 
 // -----------------------------------------------------------------------------
-// Test 2: Builder validation – missing ports should error.
+// Test 2: Builder validation – at least one listener or worker is required.
 // -----------------------------------------------------------------------------
 func TestBootServer_BuilderValidation(t *testing.T) {
-	_, err := New().
-		GRPCPort(":0").
-		Build() // missing HTTPPort
-	if err == nil {
-		t.Fatalf("Build() succeeded with missing HTTPPort; want error")
-	}
-
-	_, err = New().
-		HTTPPort(":0").
-		Build() // missing GRPCPort
+	_, err := New().Build()
 	if err == nil {
-		t.Fatalf("Build() succeeded with missing GRPCPort; want error")
+		t.Fatalf("Build() succeeded with nothing configured; want error")
 	}
 }
 
@@ -152,6 +150,238 @@ func TestBootServer_Serve_WithTemporalWorker(t *testing.T) {
 	}
 }
 
+func TestBootServer_HTTPOnly(t *testing.T) {
+	bs, err := New().HTTPPort(":0").Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if bs.lnGrpc != nil {
+		t.Fatalf("gRPC listener bound without GRPCPort")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bs.Serve(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get("http://" + bs.lnHTTP.Addr().String() + "/health")
+	if err != nil {
+		t.Fatalf("GET /health failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /health = %d, want 200", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve(ctx) did not return after context cancellation")
+	}
+}
+
+func TestBootServer_GRPCOnly(t *testing.T) {
+	bs, err := New().GRPCPort(":0").Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if bs.lnHTTP != nil {
+		t.Fatalf("HTTP listener bound without HTTPPort")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bs.Serve(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve(ctx) did not return after context cancellation")
+	}
+}
+
+func TestBootServer_WorkerOnly(t *testing.T) {
+	fw := &fakeWorker{}
+	bs := &BootServer{temporalWorker: fw}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bs.Serve(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&fw.runs); got != 1 {
+		t.Fatalf("expected worker.Run to be called once, got %d", got)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve(ctx) did not return after context cancellation")
+	}
+}
+
+// blockingWorker runs until its interrupt channel closes, as worker.Worker does.
+type blockingWorker struct{ fakeWorker }
+
+func (b *blockingWorker) Run(interruptCh <-chan interface{}) error {
+	<-interruptCh
+	return nil
+}
+
+func TestBootServer_WorkerOnly_StopsOnCancel(t *testing.T) {
+	bs := &BootServer{temporalWorker: &blockingWorker{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bs.Serve(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Serve(ctx) returned %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve(ctx) did not return after context cancellation")
+	}
+}
+
+func TestBuilder_HTTPLimits(t *testing.T) {
+	bs, err := New().
+		HTTPPort(":0").
+		ReadTimeout(time.Second).
+		ReadHeaderTimeout(2 * time.Second).
+		WriteTimeout(3 * time.Second).
+		IdleTimeout(4 * time.Second).
+		MaxHeaderBytes(8 << 10).
+		MaxBodyBytes(4).
+		ShutdownTimeout(time.Second).
+		GRPCDrainTimeout(2 * time.Second).
+		AddRestController(func() *echoController { return &echoController{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	if bs.http.ReadTimeout != time.Second || bs.http.ReadHeaderTimeout != 2*time.Second ||
+		bs.http.WriteTimeout != 3*time.Second || bs.http.IdleTimeout != 4*time.Second ||
+		bs.http.MaxHeaderBytes != 8<<10 {
+		t.Fatalf("http.Server limits not applied: %+v", bs.http)
+	}
+	if bs.shutdownTimeout != time.Second || bs.grpcDrainTimeout != 2*time.Second {
+		t.Fatalf("shutdown settings not applied")
+	}
+
+	cases := []struct {
+		name string
+		body io.Reader
+		want int
+	}{
+		{"within limit", strings.NewReader("1234"), http.StatusOK},
+		{"content length over limit", strings.NewReader("12345"), http.StatusRequestEntityTooLarge},
+		{"chunked over limit", io.MultiReader(strings.NewReader("123"), strings.NewReader("45")), http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest(http.MethodPost, "/echo", tc.body)
+		rec := &statusRecorder{header: http.Header{}}
+		bs.http.Handler.ServeHTTP(rec, req)
+		if rec.status != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, rec.status, tc.want)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/grpc.health.v1.Health/Check", strings.NewReader("12345"))
+	rec := &statusRecorder{header: http.Header{}}
+	bs.http.Handler.ServeHTTP(rec, req)
+	if rec.status != http.StatusRequestEntityTooLarge {
+		t.Errorf("/api: status = %d, want %d", rec.status, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// echoController reads the request body and reports 413 when it is too large.
+type echoController struct{}
+
+func (c *echoController) Routes() []Route {
+	return []Route{{Pattern: "/echo", Method: http.MethodPost, Handler: func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}}}
+}
+
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *statusRecorder) Header() http.Header { return r.header }
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return len(b), nil
+}
+func (r *statusRecorder) WriteHeader(code int) { r.status = code }
+
+func TestBootServer_GRPCDrainTimeout_StopsHangingStreams(t *testing.T) {
+	handlerDone := make(chan struct{})
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	bs := &BootServer{
+		grpc: grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			<-stream.Context().Done() // never finishes on its own
+			close(handlerDone)
+			return nil
+		})),
+		lnGrpc:           ln,
+		grpcDrainTimeout: 100 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bs.Serve(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, err := grpc.NewClient(bs.lnGrpc.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	stream, err := conn.NewStream(context.Background(), &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, "/test.Hang/Forever")
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	_ = stream.SendMsg(&emptypb.Empty{})
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve(ctx) did not return after the drain timeout")
+	}
+	select {
+	case <-handlerDone:
+	case <-time.After(time.Second):
+		t.Fatalf("hanging stream was not closed")
+	}
+}
+
 // -----------------------------------------------------------------------------
 // helper: tiny builder that always uses ephemeral ports
 // -----------------------------------------------------------------------------
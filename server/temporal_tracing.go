@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/log"
+)
+
+// temporalTracer adapts OpenTelemetry to Temporal's tracing interceptor, so
+// spans follow a request from the gRPC handler that starts a workflow into
+// the workflow and its activities.
+type temporalTracer struct {
+	interceptor.BaseTracer
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+type temporalSpanContextKey struct{}
+
+// temporalSpan is a span started by this tracer; temporalSpanRef is a parent
+// read from a Temporal header.
+type temporalSpan struct{ trace.Span }
+type temporalSpanRef struct{ trace.SpanContext }
+
+func newTemporalTracingInterceptor(tp trace.TracerProvider, propagator propagation.TextMapPropagator) interceptor.Interceptor {
+	return interceptor.NewTracingInterceptor(&temporalTracer{
+		tracer:     tp.Tracer("github.com/SaiNageswarS/go-api-boot/server/temporal"),
+		propagator: propagator,
+	})
+}
+
+func (t *temporalTracer) Options() interceptor.TracerOptions {
+	return interceptor.TracerOptions{
+		SpanContextKey: temporalSpanContextKey{},
+		HeaderKey:      "_tracer-data",
+	}
+}
+
+func (t *temporalTracer) UnmarshalSpan(m map[string]string) (interceptor.TracerSpanRef, error) {
+	ctx := t.propagator.Extract(context.Background(), propagation.MapCarrier(m))
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil, errors.New("no span context in temporal header")
+	}
+	return &temporalSpanRef{sc}, nil
+}
+
+func (t *temporalTracer) MarshalSpan(span interceptor.TracerSpan) (map[string]string, error) {
+	carrier := propagation.MapCarrier{}
+	t.propagator.Inject(trace.ContextWithSpan(context.Background(), span.(*temporalSpan).Span), carrier)
+	return carrier, nil
+}
+
+func (t *temporalTracer) SpanFromContext(ctx context.Context) interceptor.TracerSpan {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil
+	}
+	return &temporalSpan{span}
+}
+
+func (t *temporalTracer) ContextWithSpan(ctx context.Context, span interceptor.TracerSpan) context.Context {
+	return trace.ContextWithSpan(ctx, span.(*temporalSpan).Span)
+}
+
+func (t *temporalTracer) StartSpan(opts *interceptor.TracerStartSpanOptions) (interceptor.TracerSpan, error) {
+	ctx := context.Background()
+	switch parent := opts.Parent.(type) {
+	case *temporalSpan:
+		ctx = trace.ContextWithSpan(ctx, parent.Span)
+	case *temporalSpanRef:
+		ctx = trace.ContextWithRemoteSpanContext(ctx, parent.SpanContext)
+	}
+
+	// Run*/Handle* execute work; everything else (start, signal, query…) calls out
+	kind := trace.SpanKindClient
+	if strings.HasPrefix(opts.Operation, "Run") || strings.HasPrefix(opts.Operation, "Handle") {
+		kind = trace.SpanKindServer
+	}
+	attrs := make([]attribute.KeyValue, 0, len(opts.Tags))
+	for k, v := range opts.Tags {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	_, span := t.tracer.Start(ctx, t.SpanName(opts),
+		trace.WithTimestamp(opts.Time),
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...))
+	return &temporalSpan{span}, nil
+}
+
+// GetLogger adds the trace and span id to workflow and activity loggers.
+func (t *temporalTracer) GetLogger(logger log.Logger, ref interceptor.TracerSpanRef) log.Logger {
+	var sc trace.SpanContext
+	switch r := ref.(type) {
+	case *temporalSpan:
+		sc = r.SpanContext()
+	case *temporalSpanRef:
+		sc = r.SpanContext
+	default:
+		return logger
+	}
+	return log.With(logger, "trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+}
+
+func (s *temporalSpan) Finish(opts *interceptor.TracerFinishSpanOptions) {
+	if opts.Error != nil {
+		s.RecordError(opts.Error)
+		s.SetStatus(codes.Error, opts.Error.Error())
+	}
+	s.End()
+}
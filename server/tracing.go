@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+var tracerProviderType = reflect.TypeOf((*trace.TracerProvider)(nil)).Elem()
+
+// tracePropagator carries W3C trace context and baggage.
+var tracePropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// traceMiddleware starts a span named after route for every request, or
+// returns next unchanged when tracing is off.
+func traceMiddleware(tp trace.TracerProvider, route string, next http.Handler) http.Handler {
+	if tp == nil {
+		return next
+	}
+	// re-inject the server span so handlers that forward the request (the
+	// web proxy into gRPC) continue it rather than the caller's span
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r)
+	})
+	return otelhttp.NewHandler(inner, route, otelhttp.WithTracerProvider(tp))
+}
+
+// traceTagsUnaryInterceptor tags the call with its trace and span id, so the
+// grpc_zap logs and ctxzap loggers of handlers carry them.
+func traceTagsUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		setTraceTags(ctx)
+		return handler(ctx, req)
+	}
+}
+
+func traceTagsStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		setTraceTags(ss.Context())
+		return handler(srv, ss)
+	}
+}
+
+func setTraceTags(ctx context.Context) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	grpc_ctxtags.Extract(ctx).
+		Set("trace_id", sc.TraceID().String()).
+		Set("span_id", sc.SpanID().String())
+}
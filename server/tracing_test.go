@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/testutil"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.temporal.io/sdk/interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// inMemoryTracing returns a recording provider and restores the global
+// provider and propagator that Build replaces.
+func inMemoryTracing(t *testing.T) (trace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	tp, exporter := testutil.NewInMemoryTracing()
+	origTP, origProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(origTP)
+		otel.SetTextMapPropagator(origProp)
+	})
+	return tp, exporter
+}
+
+func spanNamed(exporter *tracetest.InMemoryExporter, name string) (tracetest.SpanStub, bool) {
+	for _, s := range exporter.GetSpans() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+// tracedController reports the traceparent its handler receives.
+type tracedController struct {
+	tp       trace.TracerProvider
+	received string
+}
+
+func (c *tracedController) Routes() []Route {
+	return []Route{{Pattern: "/traced", Method: http.MethodGet, Handler: func(w http.ResponseWriter, r *http.Request) {
+		c.received = r.Header.Get("traceparent")
+	}}}
+}
+
+func TestBuilder_WithTracing_RESTContinuesCallerTrace(t *testing.T) {
+	tp, exporter := inMemoryTracing(t)
+	ctrl := &tracedController{}
+	bs, err := New().
+		HTTPPort(":0").
+		WithTracing(tp).
+		AddRestController(func(tp trace.TracerProvider) *tracedController { ctrl.tp = tp; return ctrl }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/traced", nil)
+	req.Header.Set("traceparent", testTraceParent)
+	bs.http.Handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Same(t, tp, ctrl.tp)
+	span, ok := spanNamed(exporter, "/traced")
+	if !assert.True(t, ok, "no span for /traced") {
+		return
+	}
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	// forwarded requests continue the server span
+	assert.Contains(t, ctrl.received, span.SpanContext.SpanID().String())
+}
+
+func TestBuilder_WithTracing_GRPCSpansAndLogTags(t *testing.T) {
+	tp, exporter := inMemoryTracing(t)
+	tags := make(chan map[string]any, 1)
+	bs, err := New().
+		GRPCPort(":0").
+		WithTracing(tp).
+		Unary(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			resp, err := handler(ctx, req) // tags are set further down the chain
+			tags <- grpc_ctxtags.Extract(ctx).Values()
+			return resp, err
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bs.Serve(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, err := grpc.NewClient(bs.lnGrpc.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+
+	got := <-tags
+	span, ok := spanNamed(exporter, "grpc.health.v1.Health/Check")
+	if !assert.True(t, ok, "no span for Health/Check") {
+		return
+	}
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, span.SpanContext.TraceID().String(), got["trace_id"])
+	assert.Equal(t, span.SpanContext.SpanID().String(), got["span_id"])
+}
+
+func TestBuilder_WithTracing_Nil(t *testing.T) {
+	mockLogger := withMockLogger(func() {
+		New().WithTracing(nil)
+	})
+	assert.True(t, mockLogger.isFatalCalled)
+	assert.Equal(t, "tracer provider must not be nil", mockLogger.fatalMsg)
+}
+
+func TestTemporalTracer_PropagatesThroughHeader(t *testing.T) {
+	tp, exporter := testutil.NewInMemoryTracing()
+	tracer := &temporalTracer{tracer: tp.Tracer("test"), propagator: tracePropagator}
+
+	start, err := tracer.StartSpan(&interceptor.TracerStartSpanOptions{Operation: "StartWorkflow", Name: "Checkout", Time: time.Now()})
+	assert.NoError(t, err)
+	header, err := tracer.MarshalSpan(start)
+	assert.NoError(t, err)
+	start.Finish(&interceptor.TracerFinishSpanOptions{})
+
+	parent, err := tracer.UnmarshalSpan(header)
+	assert.NoError(t, err)
+	run, err := tracer.StartSpan(&interceptor.TracerStartSpanOptions{Parent: parent, Operation: "RunWorkflow", Name: "Checkout", Time: time.Now()})
+	assert.NoError(t, err)
+	run.Finish(&interceptor.TracerFinishSpanOptions{})
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	assert.Equal(t, "StartWorkflow:Checkout", spans[0].Name)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	assert.Equal(t, "RunWorkflow:Checkout", spans[1].Name)
+	assert.Equal(t, trace.SpanKindServer, spans[1].SpanKind)
+	assert.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+
+	_, err = tracer.UnmarshalSpan(map[string]string{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,547 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// transcodedRoute is one ServeMux pattern and the bindings sharing it; they
+// differ only by custom verb.
+type transcodedRoute struct {
+	pattern  string // "GET /v1/shelves/{p0}"
+	path     string // "/v1/shelves/{p0}", the metrics and tracing label
+	bindings []*httpBinding
+}
+
+// httpBinding maps one google.api.http rule onto a unary gRPC method.
+type httpBinding struct {
+	fullMethod   string
+	input        protoreflect.MessageType
+	output       protoreflect.MessageType
+	tmpl         *httpTemplate
+	body         string // "", "*" or a top-level field
+	responseBody string // "" or a top-level field
+}
+
+// transcodedRoutes reads the google.api.http options of every service
+// registered on srv and returns the routes to add to the HTTP mux. Services
+// whose descriptors are not in files are skipped.
+func transcodedRoutes(srv *grpc.Server, files *protoregistry.Files) ([]*transcodedRoute, error) {
+	services := make([]string, 0, len(srv.GetServiceInfo()))
+	for name := range srv.GetServiceInfo() {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+
+	var routes []*transcodedRoute
+	byPattern := map[string]*transcodedRoute{}
+	for _, name := range services {
+		d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			continue
+		}
+		sd, ok := d.(protoreflect.ServiceDescriptor)
+		if !ok {
+			continue
+		}
+		for i := 0; i < sd.Methods().Len(); i++ {
+			md := sd.Methods().Get(i)
+			rule, ok := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if !ok || rule == nil {
+				continue
+			}
+			fullMethod := fmt.Sprintf("/%s/%s", sd.FullName(), md.Name())
+			if md.IsStreamingClient() || md.IsStreamingServer() {
+				logger.Info("Skipping HTTP binding of streaming method", zap.String("method", fullMethod))
+				continue
+			}
+			for _, r := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				b, httpMethod, err := newHTTPBinding(fullMethod, md, r)
+				if err != nil {
+					return nil, fmt.Errorf("google.api.http option of %s: %w", fullMethod, err)
+				}
+				pattern := httpMethod + " " + b.tmpl.pattern
+				route, ok := byPattern[pattern]
+				if !ok {
+					route = &transcodedRoute{pattern: pattern, path: b.tmpl.pattern}
+					byPattern[pattern] = route
+					routes = append(routes, route)
+				}
+				for _, other := range route.bindings {
+					if other.tmpl.verb == b.tmpl.verb {
+						return nil, fmt.Errorf("google.api.http option of %s: %s conflicts with the binding of %s", fullMethod, pattern, other.fullMethod)
+					}
+				}
+				route.bindings = append(route.bindings, b)
+			}
+		}
+	}
+	return routes, nil
+}
+
+func newHTTPBinding(fullMethod string, md protoreflect.MethodDescriptor, rule *annotations.HttpRule) (*httpBinding, string, error) {
+	var httpMethod, path string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		httpMethod, path = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		httpMethod, path = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		httpMethod, path = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		httpMethod, path = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		httpMethod, path = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		httpMethod, path = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return nil, "", errors.New("no HTTP pattern")
+	}
+
+	tmpl, err := parseHTTPTemplate(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("path %q: %w", path, err)
+	}
+	for _, v := range tmpl.vars {
+		if _, err := lookupField(md.Input(), v.field); err != nil {
+			return nil, "", fmt.Errorf("path %q: %w", path, err)
+		}
+	}
+	for _, f := range []string{rule.GetBody(), rule.GetResponseBody()} {
+		if f == "" || f == "*" {
+			continue
+		}
+		if strings.Contains(f, ".") {
+			return nil, "", fmt.Errorf("body field %q must be a top-level field", f)
+		}
+	}
+	if f := rule.GetBody(); f != "" && f != "*" && md.Input().Fields().ByName(protoreflect.Name(f)) == nil {
+		return nil, "", fmt.Errorf("no field %q in %s", f, md.Input().FullName())
+	}
+	if f := rule.GetResponseBody(); f != "" && md.Output().Fields().ByName(protoreflect.Name(f)) == nil {
+		return nil, "", fmt.Errorf("no field %q in %s", f, md.Output().FullName())
+	}
+
+	return &httpBinding{
+		fullMethod:   fullMethod,
+		input:        messageType(md.Input()),
+		output:       messageType(md.Output()),
+		tmpl:         tmpl,
+		body:         rule.GetBody(),
+		responseBody: rule.GetResponseBody(),
+	}, httpMethod, nil
+}
+
+// messageType prefers the generated Go type, so well-known types keep their
+// JSON mapping, and falls back to a dynamic message.
+func messageType(md protoreflect.MessageDescriptor) protoreflect.MessageType {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName()); err == nil {
+		return mt
+	}
+	return dynamicpb.NewMessageType(md)
+}
+
+// httpTemplate is a google.api.http path template translated to a ServeMux
+// pattern: "/v1/{name=shelves/*}/books:publish" becomes
+// "/v1/shelves/{p0}/books:publish" with name rebuilt as "shelves/" + p0.
+type httpTemplate struct {
+	pattern string
+	vars    []templateVar
+	// verb is checked by the handler when the last segment is a wildcard;
+	// after a literal segment it is part of the pattern.
+	verb     string
+	verbWild string
+}
+
+type templateVar struct {
+	field string
+	segs  []templateSeg
+}
+
+// templateSeg is a literal, or the name of a ServeMux wildcard.
+type templateSeg struct {
+	lit, wild string
+}
+
+func parseHTTPTemplate(path string) (*httpTemplate, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.New("must start with /")
+	}
+	rest, verb := path[1:], ""
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.ContainsAny(rest[i:], "/}") {
+		rest, verb = rest[:i], rest[i+1:]
+	}
+
+	t := &httpTemplate{}
+	var muxSegs []string
+	wilds := 0
+	wildcard := func(catchAll bool) string {
+		name := "p" + strconv.Itoa(wilds)
+		wilds++
+		if catchAll {
+			muxSegs = append(muxSegs, "{"+name+"...}")
+		} else {
+			muxSegs = append(muxSegs, "{"+name+"}")
+		}
+		return name
+	}
+	segment := func(s string) (templateSeg, error) {
+		switch {
+		case s == "*":
+			return templateSeg{wild: wildcard(false)}, nil
+		case s == "**":
+			return templateSeg{wild: wildcard(true)}, nil
+		case s == "" || strings.ContainsAny(s, "{}*"):
+			return templateSeg{}, fmt.Errorf("invalid segment %q", s)
+		}
+		muxSegs = append(muxSegs, s)
+		return templateSeg{lit: s}, nil
+	}
+
+	for len(rest) > 0 {
+		var raw string
+		if rest[0] == '{' {
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return nil, errors.New("unterminated variable")
+			}
+			raw, rest = rest[1:end], rest[end+1:]
+			field, sub, hasSub := strings.Cut(raw, "=")
+			if !hasSub {
+				sub = "*"
+			}
+			v := templateVar{field: field}
+			for _, s := range strings.Split(sub, "/") {
+				seg, err := segment(s)
+				if err != nil {
+					return nil, err
+				}
+				v.segs = append(v.segs, seg)
+			}
+			t.vars = append(t.vars, v)
+		} else {
+			raw, rest, _ = strings.Cut(rest, "/")
+			if _, err := segment(raw); err != nil {
+				return nil, err
+			}
+			if rest == "" && strings.HasSuffix(path, "/") && verb == "" {
+				return nil, errors.New("must not end with /")
+			}
+			continue
+		}
+		if rest != "" {
+			if rest[0] != '/' {
+				return nil, fmt.Errorf("variable {%s} must be a whole segment", raw)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	for i, s := range muxSegs {
+		if strings.HasSuffix(s, "...}") && i != len(muxSegs)-1 {
+			return nil, errors.New("** must be the last segment")
+		}
+	}
+	if verb != "" {
+		last := muxSegs[len(muxSegs)-1]
+		if strings.HasPrefix(last, "{") {
+			t.verb, t.verbWild = verb, strings.Trim(last, "{}.")
+		} else {
+			muxSegs[len(muxSegs)-1] = last + ":" + verb
+		}
+	}
+	t.pattern = "/" + strings.Join(muxSegs, "/")
+	return t, nil
+}
+
+// match returns the value of every path variable of r, or false when the
+// request carries another verb.
+func (t *httpTemplate) match(r *http.Request) (map[string]string, bool) {
+	values := func(name string) string { return r.PathValue(name) }
+	if t.verb != "" {
+		last, ok := strings.CutSuffix(r.PathValue(t.verbWild), ":"+t.verb)
+		if !ok {
+			return nil, false
+		}
+		values = func(name string) string {
+			if name == t.verbWild {
+				return last
+			}
+			return r.PathValue(name)
+		}
+	}
+
+	vars := make(map[string]string, len(t.vars))
+	for _, v := range t.vars {
+		parts := make([]string, len(v.segs))
+		for i, s := range v.segs {
+			parts[i] = s.lit
+			if s.wild != "" {
+				parts[i] = values(s.wild)
+			}
+		}
+		vars[v.field] = strings.Join(parts, "/")
+	}
+	return vars, true
+}
+
+// dispatch serves r with the binding whose verb matches, preferring bindings
+// with a verb over one without.
+func (route *transcodedRoute) dispatch(w http.ResponseWriter, r *http.Request, srv http.Handler) {
+	var fallback *httpBinding
+	for _, b := range route.bindings {
+		if b.tmpl.verb == "" {
+			fallback = b
+			continue
+		}
+		if vars, ok := b.tmpl.match(r); ok {
+			b.serve(w, r, vars, srv)
+			return
+		}
+	}
+	if fallback == nil {
+		writeJSONError(w, r, http.StatusNotFound, status.New(codes.NotFound, "Not Found"))
+		return
+	}
+	vars, _ := fallback.tmpl.match(r)
+	fallback.serve(w, r, vars, srv)
+}
+
+// handleRoute registers h on mux, turning the panic on a pattern that
+// conflicts with an existing route into an error.
+func handleRoute(mux *http.ServeMux, pattern string, h http.Handler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+	mux.Handle(pattern, h)
+	return nil
+}
+
+// handler returns route as an http.Handler calling srv.
+func (route *transcodedRoute) handler(srv http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { route.dispatch(w, r, srv) })
+}
+
+var transcodeUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+var transcodeMarshal = protojson.MarshalOptions{EmitUnpopulated: true}
+
+func (b *httpBinding) serve(w http.ResponseWriter, r *http.Request, vars map[string]string, srv http.Handler) {
+	in := b.input.New().Interface()
+	if st := b.bind(r, in, vars); st != nil {
+		writeJSONError(w, r, httpStatusFromCode(st.Code()), st)
+		return
+	}
+	payload, err := proto.Marshal(in)
+	if err != nil {
+		WriteJSONError(w, r, fmt.Errorf("encoding request: %w", err))
+		return
+	}
+
+	resp := newGRPCResponseBuffer()
+	srv.ServeHTTP(resp, newGRPCRequest(r, b.fullMethod, bytes.NewReader(grpcFrame(payload))))
+
+	if st := resp.status(); st.Code() != codes.OK {
+		resp.copyHeaders(w.Header())
+		writeJSONError(w, r, httpStatusFromCode(st.Code()), st)
+		return
+	}
+	out := b.output.New().Interface()
+	if err := resp.message(out); err != nil {
+		WriteJSONError(w, r, err)
+		return
+	}
+	body, err := transcodeMarshal.Marshal(out)
+	if err == nil && b.responseBody != "" {
+		body, err = jsonField(body, out.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(b.responseBody)))
+	}
+	if err != nil {
+		WriteJSONError(w, r, fmt.Errorf("encoding response: %w", err))
+		return
+	}
+
+	resp.copyHeaders(w.Header())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// bind fills in from the body, then the query string, then the path, later
+// sources overriding earlier ones.
+func (b *httpBinding) bind(r *http.Request, in proto.Message, vars map[string]string) *status.Status {
+	if b.body != "" && r.Body != nil {
+		raw, err := io.ReadAll(r.Body)
+		var maxBytes *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytes):
+			return status.Newf(codes.InvalidArgument, "request body exceeds %d bytes", maxBytes.Limit)
+		case err != nil:
+			return status.Newf(codes.InvalidArgument, "reading request body: %v", err)
+		}
+		if len(bytes.TrimSpace(raw)) > 0 {
+			if b.body != "*" {
+				raw = []byte(`{"` + b.body + `":` + string(raw) + `}`)
+			}
+			if err := mergeJSON(in, raw); err != nil {
+				return status.Newf(codes.InvalidArgument, "invalid JSON body: %v", err)
+			}
+		}
+	}
+
+	if b.body != "*" {
+		query := r.URL.Query()
+		keys := make([]string, 0, len(query))
+		for k := range query {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, bound := vars[k]; bound || (b.body != "" && strings.Split(k, ".")[0] == b.body) {
+				continue
+			}
+			fd, err := lookupField(in.ProtoReflect().Descriptor(), k)
+			if err != nil || fd.IsMap() {
+				continue // unknown parameters, e.g. cache busters, are ignored
+			}
+			if err := setFieldPath(in, fd, k, query[k]); err != nil {
+				return badRequest(k, fmt.Sprintf("invalid query parameter %q: %v", k, err))
+			}
+		}
+	}
+
+	for _, v := range b.tmpl.vars {
+		fd, _ := lookupField(in.ProtoReflect().Descriptor(), v.field)
+		if err := setFieldPath(in, fd, v.field, []string{vars[v.field]}); err != nil {
+			return badRequest(v.field, fmt.Sprintf("invalid path parameter %q: %v", v.field, err))
+		}
+	}
+	return nil
+}
+
+// lookupField resolves a dotted field path, by proto or JSON names.
+func lookupField(md protoreflect.MessageDescriptor, path string) (protoreflect.FieldDescriptor, error) {
+	var fd protoreflect.FieldDescriptor
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return nil, fmt.Errorf("%s is not a message field", fd.Name())
+			}
+			md = fd.Message()
+		}
+		fd = md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("no field %q in %s", name, md.FullName())
+		}
+	}
+	return fd, nil
+}
+
+// setFieldPath sets the field at path from string values by way of the
+// field's JSON form, so every type protojson understands, including
+// well-known types such as Timestamp, can be bound.
+func setFieldPath(m proto.Message, fd protoreflect.FieldDescriptor, path string, values []string) error {
+	jsonValues := make([]any, len(values))
+	for i, raw := range values {
+		v, err := scalarJSON(fd, raw)
+		if err != nil {
+			return err
+		}
+		jsonValues[i] = v
+	}
+	var value any = jsonValues[len(jsonValues)-1]
+	if fd.IsList() {
+		value = jsonValues
+	}
+
+	names := strings.Split(path, ".")
+	for i := len(names) - 1; i >= 0; i-- {
+		value = map[string]any{names[i]: value}
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if fd.IsList() {
+		// query values replace, not extend, the list, which may sit in a
+		// submessage
+		parent := m.ProtoReflect()
+		for _, name := range names[:len(names)-1] {
+			field, _ := lookupField(parent.Descriptor(), name)
+			if !parent.Has(field) {
+				parent = nil
+				break
+			}
+			parent = parent.Get(field).Message()
+		}
+		if parent != nil {
+			parent.Clear(fd)
+		}
+	}
+	if err := mergeJSON(m, raw); err != nil {
+		// protojson reports the whole document; the caller names the field
+		return errors.New(strings.TrimPrefix(err.Error(), "proto: "))
+	}
+	return nil
+}
+
+func scalarJSON(fd protoreflect.FieldDescriptor, raw string) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("expected a boolean")
+		}
+		return b, nil
+	case protoreflect.EnumKind:
+		if n, err := strconv.ParseInt(raw, 10, 32); err == nil {
+			return n, nil
+		}
+	}
+	return raw, nil
+}
+
+// mergeJSON unmarshals raw into a new message and merges it into m, keeping
+// the fields m already has.
+func mergeJSON(m proto.Message, raw []byte) error {
+	tmp := m.ProtoReflect().New().Interface()
+	if err := transcodeUnmarshal.Unmarshal(raw, tmp); err != nil {
+		return err
+	}
+	proto.Merge(m, tmp)
+	return nil
+}
+
+// jsonField extracts one field from a marshalled message.
+func jsonField(body []byte, fd protoreflect.FieldDescriptor) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	if v, ok := fields[fd.JSONName()]; ok {
+		return v, nil
+	}
+	return []byte("null"), nil
+}
@@ -0,0 +1,390 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaiNageswarS/go-api-boot/auth"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var (
+	libraryOnce sync.Once
+	libraryDesc protoreflect.ServiceDescriptor
+)
+
+// librarySvc returns the descriptor of a small annotated service, registered
+// in protoregistry.GlobalFiles as generated code would be:
+//
+//	service Library {
+//	  rpc GetBook(GetBookRequest) returns (Book)            { get: "/v1/{name=shelves/*/books/*}" idempotency_level: NO_SIDE_EFFECTS }
+//	  rpc UpdateBook(UpdateBookRequest) returns (Book)      { patch: "/v1/{book.name=shelves/*/books/*}" body: "book" }
+//	  rpc CreateBook(Book) returns (Book)                   { post: "/v1/books" body: "*" additional_bindings { post: "/v1/books:import" body: "*" } }
+//	  rpc ArchiveBook(GetBookRequest) returns (Book)        { post: "/v1/{name=shelves/*/books/*}:archive" }
+//	  rpc RestoreBook(GetBookRequest) returns (Book)        { post: "/v1/{name=shelves/*/books/*}:restore" }
+//	  rpc ListBooks(GetBookRequest) returns (ListBooksResponse) { get: "/v1/books" response_body: "books" }
+//	  rpc WatchBooks(GetBookRequest) returns (stream Book)  { get: "/v1/books:watch" idempotency_level: NO_SIDE_EFFECTS }
+//	  rpc ReindexBooks(GetBookRequest) returns (stream Book) { post: "/v1/books:reindex" }
+//	}
+func librarySvc(t *testing.T) protoreflect.ServiceDescriptor {
+	libraryOnce.Do(func() {
+		str := func(name string, n int32) *descriptorpb.FieldDescriptorProto {
+			return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(n),
+				Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}
+		}
+		typed := func(f *descriptorpb.FieldDescriptorProto, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+			f.Type = typ.Enum()
+			if typeName != "" {
+				f.TypeName = proto.String(typeName)
+			}
+			return f
+		}
+		repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+			f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			return f
+		}
+		method := func(name, in, out string, rule *annotations.HttpRule, stream bool) *descriptorpb.MethodDescriptorProto {
+			opts := &descriptorpb.MethodOptions{}
+			proto.SetExtension(opts, annotations.E_Http, rule)
+			return &descriptorpb.MethodDescriptorProto{Name: proto.String(name), InputType: proto.String(in),
+				OutputType: proto.String(out), Options: opts, ServerStreaming: proto.Bool(stream)}
+		}
+		noSideEffects := func(m *descriptorpb.MethodDescriptorProto) *descriptorpb.MethodDescriptorProto {
+			m.Options.IdempotencyLevel = descriptorpb.MethodOptions_NO_SIDE_EFFECTS.Enum()
+			return m
+		}
+		bookPath := "/v1/{name=shelves/*/books/*}"
+
+		fdp := &descriptorpb.FileDescriptorProto{
+			Name:    proto.String("server/transcoding_test.proto"),
+			Package: proto.String("transcodingtest"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{
+				{Name: proto.String("Book"), Field: []*descriptorpb.FieldDescriptorProto{
+					str("name", 1), str("title", 2),
+					typed(str("pages", 3), descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+				}},
+				{Name: proto.String("Filter"), Field: []*descriptorpb.FieldDescriptorProto{str("author", 1), repeated(str("tags", 2))}},
+				{Name: proto.String("GetBookRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+					str("name", 1),
+					typed(str("full", 2), descriptorpb.FieldDescriptorProto_TYPE_BOOL, ""),
+					repeated(str("tags", 3)),
+					typed(str("filter", 4), descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".transcodingtest.Filter"),
+				}},
+				{Name: proto.String("UpdateBookRequest"), Field: []*descriptorpb.FieldDescriptorProto{
+					typed(str("book", 1), descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".transcodingtest.Book"),
+					str("etag", 2),
+				}},
+				{Name: proto.String("ListBooksResponse"), Field: []*descriptorpb.FieldDescriptorProto{
+					repeated(typed(str("books", 1), descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".transcodingtest.Book")),
+					str("next_page_token", 2),
+				}},
+			},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("Library"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					noSideEffects(method("GetBook", ".transcodingtest.GetBookRequest", ".transcodingtest.Book",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: bookPath}}, false)),
+					method("UpdateBook", ".transcodingtest.UpdateBookRequest", ".transcodingtest.Book",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{book.name=shelves/*/books/*}"}, Body: "book"}, false),
+					method("CreateBook", ".transcodingtest.Book", ".transcodingtest.Book",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/books"}, Body: "*",
+							AdditionalBindings: []*annotations.HttpRule{{Pattern: &annotations.HttpRule_Post{Post: "/v1/books:import"}, Body: "*"}}}, false),
+					method("ArchiveBook", ".transcodingtest.GetBookRequest", ".transcodingtest.Book",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: bookPath + ":archive"}}, false),
+					method("RestoreBook", ".transcodingtest.GetBookRequest", ".transcodingtest.Book",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: bookPath + ":restore"}}, false),
+					method("ListBooks", ".transcodingtest.GetBookRequest", ".transcodingtest.ListBooksResponse",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/books"}, ResponseBody: "books"}, false),
+					noSideEffects(method("WatchBooks", ".transcodingtest.GetBookRequest", ".transcodingtest.Book",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/books:watch"}}, true)),
+					method("ReindexBooks", ".transcodingtest.GetBookRequest", ".transcodingtest.Book",
+						&annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/books:reindex"}}, true),
+				},
+			}},
+		}
+		fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+		if err == nil {
+			err = protoregistry.GlobalFiles.RegisterFile(fd)
+		}
+		if err != nil {
+			panic(err)
+		}
+		libraryDesc = fd.Services().ByName("Library")
+	})
+	return libraryDesc
+}
+
+// libraryCall is what the fake Library service received.
+type libraryCall struct {
+	method string
+	in     string // protojson
+}
+
+// registerLibrary registers a Library service that records each call and
+// answers with reply(method), given as protojson.
+func registerLibrary(t *testing.T, calls *[]libraryCall, reply func(method string) (string, error)) func(grpc.ServiceRegistrar, any) {
+	sd := librarySvc(t)
+	desc := grpc.ServiceDesc{ServiceName: string(sd.FullName()), HandlerType: (*any)(nil)}
+	for i := 0; i < sd.Methods().Len(); i++ {
+		md := sd.Methods().Get(i)
+		fullMethod := "/" + string(sd.FullName()) + "/" + string(md.Name())
+		impl := func(ctx context.Context, req any) (any, error) {
+			in, _ := protojson.Marshal(req.(proto.Message))
+			*calls = append(*calls, libraryCall{method: string(md.Name()), in: string(in)})
+			_ = grpc.SetTrailer(ctx, metadata.Pairs("x-served-by", "library"))
+			body, err := reply(string(md.Name()))
+			if err != nil {
+				return nil, err
+			}
+			out := dynamicpb.NewMessage(md.Output())
+			return out, protojson.Unmarshal([]byte(body), out)
+		}
+		if md.IsStreamingServer() {
+			// sends the reply twice
+			desc.Streams = append(desc.Streams, grpc.StreamDesc{StreamName: string(md.Name()), ServerStreams: true,
+				Handler: func(_ any, ss grpc.ServerStream) error {
+					in := dynamicpb.NewMessage(md.Input())
+					if err := ss.RecvMsg(in); err != nil {
+						return err
+					}
+					out, err := impl(ss.Context(), in)
+					if err != nil {
+						return err
+					}
+					for i := 0; i < 2; i++ {
+						if err := ss.SendMsg(out); err != nil {
+							return err
+						}
+					}
+					return nil
+				}})
+			continue
+		}
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: string(md.Name()),
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := dynamicpb.NewMessage(md.Input())
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return impl(ctx, in)
+				}
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, impl)
+			},
+		})
+	}
+	return func(r grpc.ServiceRegistrar, srv any) { r.RegisterService(&desc, srv) }
+}
+
+func buildLibrary(t *testing.T, b *Builder, reply func(string) (string, error)) (http.Handler, *[]libraryCall) {
+	t.Helper()
+	calls := &[]libraryCall{}
+	bs, err := b.
+		HTTPPort(":0").
+		EnableHTTPTranscoding().
+		RegisterService(registerLibrary(t, calls, reply), func() *struct{} { return &struct{}{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	t.Cleanup(func() { bs.lnHTTP.Close() })
+	return bs.http.Handler, calls
+}
+
+func TestParseHTTPTemplate(t *testing.T) {
+	cases := []struct {
+		path, pattern, verb string
+		vars                []string
+	}{
+		{"/v1/users/{id}", "/v1/users/{p0}", "", []string{"id"}},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/{p0}/books/{p1}", "", []string{"name"}},
+		{"/v1/{name=files/**}", "/v1/files/{p0...}", "", []string{"name"}},
+		{"/v1/books:import", "/v1/books:import", "", nil},
+		{"/v1/{name=books/*}:archive", "/v1/books/{p0}", "archive", []string{"name"}},
+		{"/v1/*/{user.id}", "/v1/{p0}/{p1}", "", []string{"user.id"}},
+	}
+	for _, c := range cases {
+		tmpl, err := parseHTTPTemplate(c.path)
+		if !assert.NoError(t, err, c.path) {
+			continue
+		}
+		assert.Equal(t, c.pattern, tmpl.pattern, c.path)
+		assert.Equal(t, c.verb, tmpl.verb, c.path)
+		var vars []string
+		for _, v := range tmpl.vars {
+			vars = append(vars, v.field)
+		}
+		assert.Equal(t, c.vars, vars, c.path)
+	}
+
+	for _, bad := range []string{"v1/users", "/v1/{id", "/v1/{name=**}/x", "/v1//x", "/v1/users/"} {
+		_, err := parseHTTPTemplate(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestTranscoding_BindsPathQueryAndBody(t *testing.T) {
+	h, calls := buildLibrary(t, New().PublicMethods("/transcodingtest.Library/*"), func(method string) (string, error) {
+		if method == "ListBooks" {
+			return `{"books":[{"name":"b1"}],"nextPageToken":"n"}`, nil
+		}
+		return `{"name":"shelves/1/books/2","title":"Dune","pages":412}`, nil
+	})
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		var r *http.Request
+		if body == "" {
+			r = httptest.NewRequest(method, target, nil)
+		} else {
+			r = httptest.NewRequest(method, target, strings.NewReader(body))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/v1/shelves/1/books/2?full=true&tags=a&tags=b&filter.author=Herbert&filter.tags=c&filter.tags=d&name=ignored&_=123", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"shelves/1/books/2","title":"Dune","pages":412}`, rec.Body.String())
+	assert.JSONEq(t, `{"name":"shelves/1/books/2","full":true,"tags":["a","b"],"filter":{"author":"Herbert","tags":["c","d"]}}`, (*calls)[0].in)
+
+	rec = serve(http.MethodPatch, "/v1/shelves/1/books/2?etag=e1", `{"title":"Dune Messiah","name":"ignored"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"book":{"name":"shelves/1/books/2","title":"Dune Messiah"},"etag":"e1"}`, (*calls)[1].in)
+
+	rec = serve(http.MethodPost, "/v1/books:import", `{"title":"Emma","pages":"300"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "CreateBook", (*calls)[2].method)
+	assert.JSONEq(t, `{"title":"Emma","pages":300}`, (*calls)[2].in)
+
+	serve(http.MethodPost, "/v1/shelves/1/books/2:restore", "")
+	serve(http.MethodPost, "/v1/shelves/1/books/2:archive", "")
+	assert.Equal(t, "RestoreBook", (*calls)[3].method)
+	assert.Equal(t, "ArchiveBook", (*calls)[4].method)
+	assert.JSONEq(t, `{"name":"shelves/1/books/2"}`, (*calls)[4].in)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/v1/shelves/1/books/2:burn", "").Code)
+
+	rec = serve(http.MethodGet, "/v1/books", "")
+	assert.JSONEq(t, `[{"name":"b1","title":"","pages":0}]`, rec.Body.String())
+
+	rec = serve(http.MethodGet, "/v1/shelves/1/books/2?full=maybe", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, `invalid query parameter "full": expected a boolean`, decodeEnvelope(t, rec).Message)
+
+	rec = serve(http.MethodPost, "/v1/books", `{"title":`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/v1/books:watch", "").Code, "streaming methods are not transcoded")
+	assert.Len(t, *calls, 6)
+}
+
+func TestTranscoding_RunsInterceptors(t *testing.T) {
+	t.Setenv("ACCESS-SECRET", "test-secret")
+	token, _ := auth.GetToken("t1", "u1", "user")
+	h, calls := buildLibrary(t, New().
+		RateLimit("/transcodingtest.Library/GetBook", RateLimit{Key: RateLimitByUser, Limit: 1, Window: time.Minute}),
+		func(string) (string, error) { return "", status.Error(codes.NotFound, "no such book: ü") })
+	get := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/shelves/1/books/2", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := get("")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "UNAUTHENTICATED", decodeEnvelope(t, rec).Status)
+	assert.Empty(t, *calls)
+
+	rec = get(token)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "no such book: ü", decodeEnvelope(t, rec).Message)
+	assert.NotEmpty(t, rec.Header().Get("X-Request-Id"))
+
+	rec = get(token)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Len(t, decodeEnvelope(t, rec).Details, 1)
+	assert.Len(t, *calls, 1)
+}
+
+type bookController struct{}
+
+func (bookController) Routes() []Route {
+	return []Route{{Pattern: "GET /v1/shelves/{shelf}/books/{book}", Handler: func(http.ResponseWriter, *http.Request) {}}}
+}
+
+func TestTranscoding_ConflictFailsBuild(t *testing.T) {
+	calls := &[]libraryCall{}
+	_, err := New().
+		HTTPPort(":0").
+		EnableHTTPTranscoding().
+		AddRestController(func() bookController { return bookController{} }).
+		RegisterService(registerLibrary(t, calls, nil), func() *struct{} { return &struct{}{} }).
+		Build()
+	assert.ErrorContains(t, err, "HTTP transcoding failed")
+}
+
+func TestTranscoding_DuplicateBindingFailsBuild(t *testing.T) {
+	const svcName = "conflicttest.Things"
+	if _, err := protoregistry.GlobalFiles.FindDescriptorByName(svcName); err != nil {
+		method := func(name string) *descriptorpb.MethodDescriptorProto {
+			opts := &descriptorpb.MethodOptions{}
+			proto.SetExtension(opts, annotations.E_Http, &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/x/{id}"}})
+			return &descriptorpb.MethodDescriptorProto{Name: proto.String(name), InputType: proto.String(".conflicttest.Thing"),
+				OutputType: proto.String(".conflicttest.Thing"), Options: opts}
+		}
+		fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+			Name:    proto.String("server/transcoding_conflict_test.proto"),
+			Package: proto.String("conflicttest"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Thing"), Field: []*descriptorpb.FieldDescriptorProto{{
+				Name: proto.String("id"), Number: proto.Int32(1),
+				Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()}}}},
+			Service: []*descriptorpb.ServiceDescriptorProto{{Name: proto.String("Things"),
+				Method: []*descriptorpb.MethodDescriptorProto{method("GetThing"), method("FetchThing")}}},
+		}, protoregistry.GlobalFiles)
+		if err == nil {
+			err = protoregistry.GlobalFiles.RegisterFile(fd)
+		}
+		if err != nil {
+			t.Fatalf("register descriptor: %v", err)
+		}
+	}
+
+	noop := func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) { return nil, nil }
+	_, err := New().
+		HTTPPort(":0").
+		EnableHTTPTranscoding().
+		RegisterService(func(s grpc.ServiceRegistrar, impl any) {
+			s.RegisterService(&grpc.ServiceDesc{ServiceName: svcName, HandlerType: (*any)(nil), Methods: []grpc.MethodDesc{
+				{MethodName: "GetThing", Handler: noop},
+				{MethodName: "FetchThing", Handler: noop},
+			}}, impl)
+		}, func() *struct{} { return &struct{}{} }).
+		Build()
+	assert.ErrorContains(t, err, "HTTP transcoding failed")
+	assert.ErrorContains(t, err, "GET /v1/x/{p0} conflicts")
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type visitState int
+
+const (
+	unvisited visitState = iota
+	visiting
+	visited
+)
+
+// graphValidator walks the dependency graph without invoking any provider and
+// collects every missing dependency and cycle, so Build can fail with one report.
+type graphValidator struct {
+	ctn      *container
+	state    map[visitKey]visitState
+	stack    []string
+	reported map[string]bool
+}
+
+func newGraphValidator(ctn *container) *graphValidator {
+	return &graphValidator{
+		ctn:      ctn,
+		state:    map[visitKey]visitState{},
+		reported: map[string]bool{},
+	}
+}
+
+// visitKey distinguishes visits inside a request scope, where scoped values
+// and context.Context are injectable, from build-time visits.
+type visitKey struct {
+	key     any
+	inScope bool
+}
+
+// checkFactory validates every parameter of a service / controller / activity
+// factory. consumer describes the factory in error messages.
+func (g *graphValidator) checkFactory(consumer string, fn reflect.Value) []error {
+	var errs []error
+	for i := 0; i < fn.Type().NumIn(); i++ {
+		errs = append(errs, g.visit(fn.Type().In(i), consumer, false)...)
+	}
+	return errs
+}
+
+// checkScoped validates the dependencies of request-scoped providers.
+func (g *graphValidator) checkScoped() []error {
+	var scoped []reflect.Type
+	for t, l := range g.ctn.lifetimes {
+		if l == Scoped {
+			scoped = append(scoped, t)
+		}
+	}
+	sort.Slice(scoped, func(i, j int) bool { return scoped[i].String() < scoped[j].String() })
+
+	var errs []error
+	for _, t := range scoped {
+		errs = append(errs, g.visit(t, "request scope", true)...)
+	}
+	return errs
+}
+
+func (g *graphValidator) visit(t reflect.Type, consumer string, inScope bool) []error {
+	if inScope && t == contextType {
+		return nil
+	}
+
+	key, label := any(t), t.String()
+	var provider reflect.Value
+	var found bool
+
+	if k, ok := asNamed(t); ok {
+		key, label = k, fmt.Sprintf("%v named %q", k.typ, k.name)
+		_, found = g.ctn.named[k]
+		if !found {
+			provider, found = g.ctn.namedProviders[k]
+		}
+	} else {
+		_, found = g.ctn.singletons[t]
+		if !found {
+			provider, found = g.ctn.providers[t]
+		}
+		if !found && t.Kind() == reflect.Slice {
+			if members, ok := g.ctn.groups[t.Elem()]; ok {
+				return g.visitGroup(t, members, inScope)
+			}
+		}
+	}
+
+	if !found {
+		return g.report(fmt.Sprintf("no provider for %s (requested by %s)", label, consumer))
+	}
+	if !inScope && g.ctn.lifetimes[t] == Scoped {
+		return g.report(fmt.Sprintf("request-scoped %s cannot be injected into %s; use server.Resolve in the handler", label, consumer))
+	}
+
+	vk := visitKey{key: key, inScope: inScope}
+	switch g.state[vk] {
+	case visited:
+		return nil
+	case visiting:
+		return g.cycle(label)
+	}
+
+	if !provider.IsValid() { // plain singleton – nothing to walk
+		g.state[vk] = visited
+		return nil
+	}
+
+	// singletons are built once at startup, outside of any request
+	depInScope := inScope && g.ctn.lifetimes[t] != Singleton
+
+	g.state[vk] = visiting
+	g.stack = append(g.stack, label)
+
+	var errs []error
+	for i := 0; i < provider.Type().NumIn(); i++ {
+		errs = append(errs, g.visit(provider.Type().In(i), "provider of "+label, depInScope)...)
+	}
+
+	g.stack = g.stack[:len(g.stack)-1]
+	g.state[vk] = visited
+	return errs
+}
+
+// visitGroup walks the dependencies of every provider contributing to []T.
+func (g *graphValidator) visitGroup(t reflect.Type, members []groupMember, inScope bool) []error {
+	label := t.String()
+	vk := visitKey{key: t, inScope: inScope}
+	switch g.state[vk] {
+	case visited:
+		return nil
+	case visiting:
+		return g.cycle(label)
+	}
+
+	g.state[vk] = visiting
+	g.stack = append(g.stack, label)
+
+	var errs []error
+	for _, m := range members {
+		if !m.provider.IsValid() {
+			continue
+		}
+		for i := 0; i < m.provider.Type().NumIn(); i++ {
+			errs = append(errs, g.visit(m.provider.Type().In(i), "group member of "+label, false)...)
+		}
+	}
+
+	g.stack = g.stack[:len(g.stack)-1]
+	g.state[vk] = visited
+	return errs
+}
+
+// cycle reports the path from the first occurrence of label on the stack.
+func (g *graphValidator) cycle(label string) []error {
+	start := 0
+	for i, l := range g.stack {
+		if l == label {
+			start = i
+			break
+		}
+	}
+	path := append(append([]string{}, g.stack[start:]...), label)
+	return g.report("dependency cycle: " + strings.Join(path, " -> "))
+}
+
+// report de-duplicates messages, e.g. a missing type requested by the same
+// provider on behalf of several services is reported once.
+func (g *graphValidator) report(msg string) []error {
+	if g.reported[msg] {
+		return nil
+	}
+	g.reported[msg] = true
+	return []error{errors.New(msg)}
+}
+
+// validateGraph checks every registered factory up front. Errors are grouped
+// by the same categories Build uses when a factory fails.
+func (b *Builder) validateGraph(ctn *container) error {
+	g := newGraphValidator(ctn)
+
+	var svcErrs, restErrs, activityErrs, healthErrs, scopeErrs []error
+	for _, r := range b.reg {
+		svcErrs = append(svcErrs, g.checkFactory("service "+r.factory.Type().Out(0).String(), r.factory)...)
+	}
+	for _, f := range b.restControllerRegs {
+		restErrs = append(restErrs, g.checkFactory("REST controller "+f.Type().Out(0).String(), f)...)
+	}
+	if b.temporalClientOpts != nil {
+		for _, f := range b.activityRegs {
+			activityErrs = append(activityErrs, g.checkFactory("activity "+f.Type().Out(0).String(), f)...)
+		}
+	}
+
+	if _, ok := ctn.groups[healthCheckerType]; ok {
+		healthErrs = g.visit(reflect.SliceOf(healthCheckerType), "health checks", false)
+	}
+
+	scopeErrs = g.checkScoped()
+
+	var errs []error
+	if len(svcErrs) > 0 {
+		errs = append(errs, fmt.Errorf("service DI failed: %w", errors.Join(svcErrs...)))
+	}
+	if len(restErrs) > 0 {
+		errs = append(errs, fmt.Errorf("REST controller DI failed: %w", errors.Join(restErrs...)))
+	}
+	if len(activityErrs) > 0 {
+		errs = append(errs, fmt.Errorf("activity DI failed: %w", errors.Join(activityErrs...)))
+	}
+	if len(healthErrs) > 0 {
+		errs = append(errs, fmt.Errorf("health check DI failed: %w", errors.Join(healthErrs...)))
+	}
+	if len(scopeErrs) > 0 {
+		errs = append(errs, fmt.Errorf("request scope DI failed: %w", errors.Join(scopeErrs...)))
+	}
+	return errors.Join(errs...)
+}
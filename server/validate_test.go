@@ -0,0 +1,81 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/sdk/client"
+)
+
+// cycle types: cycA -> cycB -> cycA
+type cycA struct{}
+type cycB struct{}
+
+func TestBuild_Validate_ReportsAllMissingDependencies(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		RegisterService((&regSpy{}).fn, func(d *dep, i iface) *svc { return &svc{} }).
+		AddRestController(func(d *dep) *testRestControllerWithDep { return nil }).
+		Build()
+
+	if err == nil {
+		t.Fatalf("expected validation error, got nil")
+	}
+	msg := err.Error()
+	assert.Contains(t, msg, "service DI failed")
+	assert.Contains(t, msg, "no provider for *server.dep (requested by service *server.svc)")
+	assert.Contains(t, msg, "no provider for server.iface (requested by service *server.svc)")
+	assert.Contains(t, msg, "REST controller DI failed")
+	assert.Contains(t, msg, "no provider for *server.dep (requested by REST controller *server.testRestControllerWithDep)")
+}
+
+func TestBuild_Validate_NamesNestedConsumer(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFunc(func(a *A) *B { return &B{dep: a} }).
+		RegisterService((&regSpy{}).fn, func(b *B) *svc { return &svc{} }).
+		Build()
+
+	assert.ErrorContains(t, err, "no provider for *server.A (requested by provider of *server.B)")
+}
+
+func TestBuild_Validate_DetectsCycle(t *testing.T) {
+	called := false
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		ProvideFunc(func(*cycB) *cycA { called = true; return &cycA{} }).
+		ProvideFunc(func(*cycA) *cycB { called = true; return &cycB{} }).
+		RegisterService((&regSpy{}).fn, func(a *cycA) *svc { return &svc{} }).
+		Build()
+
+	assert.ErrorContains(t, err, "dependency cycle: *server.cycA -> *server.cycB -> *server.cycA")
+	assert.False(t, called, "providers must not run when the graph is invalid")
+	assert.Equal(t, 1, strings.Count(err.Error(), "dependency cycle"))
+}
+
+func TestBuild_Validate_NamedDependency(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		Provide(&dep{}).
+		RegisterService((&regSpy{}).fn, func(d Named[*dep, analyticsTag]) *svc { return &svc{} }).
+		Build()
+
+	assert.ErrorContains(t, err, `no provider for *server.dep named "analytics"`)
+}
+
+func TestBuild_Validate_ActivitiesBeforeTemporalDial(t *testing.T) {
+	_, err := New().
+		GRPCPort(":0").
+		HTTPPort(":0").
+		WithTemporal("queue", &client.Options{HostPort: "invalid:0"}).
+		RegisterTemporalActivity(func(d *dep) *struct{} { return &struct{}{} }).
+		Build()
+
+	assert.ErrorContains(t, err, "activity DI failed")
+	assert.ErrorContains(t, err, "no provider for *server.dep (requested by activity *struct {})")
+}
@@ -1,12 +1,18 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/SaiNageswarS/go-api-boot/logger"
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const grpcContentType = "application/grpc"
@@ -14,30 +20,72 @@ const grpcWebContentType = "application/grpc-web"
 const grpcWebTextContentType = "application/grpc-web-text"
 
 type WebProxy struct {
-	handler       http.Handler
-	endPointsFunc func() []string
+	handler http.Handler
+	// full methods of the registered services; other paths get Unimplemented
+	// in every protocol without reaching handler
+	endPoints map[string]bool
+
+	// origins allowed to open WebSockets; nil ⇒ same origin only
+	checkOrigin func(*http.Request) bool
+
+	// idle time before an SSE heartbeat; 0 ⇒ defaultSSEHeartbeat
+	sseHeartbeat time.Duration
+	// accept the SSE access_token query parameter; it is dropped either way
+	sseQueryToken bool
 }
 
+// GetWebProxy proxies to the services registered on server so far; register
+// services before calling it.
 func GetWebProxy(server *grpc.Server) WebProxy {
-	endPointsFunc := func() []string {
-		return listGRPCResources(server)
+	endPoints := map[string]bool{}
+	for _, m := range listGRPCResources(server) {
+		endPoints[m] = true
 	}
 
 	return WebProxy{
-		handler:       server,
-		endPointsFunc: endPointsFunc,
+		handler:   server,
+		endPoints: endPoints,
 	}
 }
 
+// ServeHTTP serves gRPC-Web, Connect, WebSocket and SSE requests.
 func (w WebProxy) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if isSSERequest(req) {
+		w.serveSSE(resp, req)
+		return
+	}
+	if call, ok := connectCallOf(req); ok {
+		w.serveConnect(resp, req, call)
+		return
+	}
+	if websocket.IsWebSocketUpgrade(req) {
+		w.serveWebSocket(resp, req)
+		return
+	}
+
 	grpcReq, isTextFormat := interceptGrpcRequest(req)
+	grpcReq.Header.Set(grpcWebHeader, "1")
 	grpcResp := getWebProxyResponse(resp, isTextFormat)
 	logger.Info("WebProxy.ServeHTTP: ", zap.String("Url", grpcReq.URL.Path))
 
+	if st := w.checkMethod(grpcReq.URL.Path); st != nil {
+		grpcResp.writeStatus(st.Code(), st.Message())
+		return
+	}
+
 	w.handler.ServeHTTP(grpcResp, grpcReq)
 	grpcResp.finishRequest(grpcReq)
 }
 
+// checkMethod returns Unimplemented unless fullMethod belongs to a registered
+// service.
+func (w WebProxy) checkMethod(fullMethod string) *status.Status {
+	if w.endPoints[fullMethod] {
+		return nil
+	}
+	return status.Newf(codes.Unimplemented, "unknown method %s", fullMethod)
+}
+
 func listGRPCResources(server *grpc.Server) []string {
 	ret := []string{}
 	for serviceName, serviceInfo := range server.GetServiceInfo() {
@@ -48,3 +96,56 @@ func listGRPCResources(server *grpc.Server) []string {
 	}
 	return ret
 }
+
+// webProxyPatterns returns the mux patterns the proxy claims under prefix:
+// one subtree per registered service, so unknown methods of a service get
+// Unimplemented while other paths are left to the rest of the mux.
+func webProxyPatterns(server *grpc.Server, prefix string) []string {
+	ret := []string{}
+	for serviceName := range server.GetServiceInfo() {
+		ret = append(ret, prefix+"/"+serviceName+"/")
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+type grpcServiceInfo struct {
+	Name    string           `json:"name"`
+	Methods []grpcMethodInfo `json:"methods"`
+}
+
+type grpcMethodInfo struct {
+	Name            string `json:"name"`
+	Path            string `json:"path"`
+	ClientStreaming bool   `json:"clientStreaming"`
+	ServerStreaming bool   `json:"serverStreaming"`
+}
+
+// listGRPCServices describes the registered services, sorted by name, with
+// the proxy paths of their methods under prefix.
+func listGRPCServices(server *grpc.Server, prefix string) []grpcServiceInfo {
+	ret := []grpcServiceInfo{}
+	for serviceName, serviceInfo := range server.GetServiceInfo() {
+		svc := grpcServiceInfo{Name: serviceName, Methods: []grpcMethodInfo{}}
+		for _, methodInfo := range serviceInfo.Methods {
+			svc.Methods = append(svc.Methods, grpcMethodInfo{
+				Name:            methodInfo.Name,
+				Path:            fmt.Sprintf("%s/%s/%s", prefix, serviceName, methodInfo.Name),
+				ClientStreaming: methodInfo.IsClientStream,
+				ServerStreaming: methodInfo.IsServerStream,
+			})
+		}
+		sort.Slice(svc.Methods, func(i, j int) bool { return svc.Methods[i].Name < svc.Methods[j].Name })
+		ret = append(ret, svc)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// servicesHandler serves the services reachable through the proxy as JSON.
+func servicesHandler(server *grpc.Server, prefix string) http.HandlerFunc {
+	return methodFilterHandler(http.MethodGet, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"services": listGRPCServices(server, prefix)})
+	})
+}
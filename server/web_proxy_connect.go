@@ -0,0 +1,444 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SaiNageswarS/go-api-boot/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Connect protocol (https://connectrpc.com/docs/protocol) content types.
+const (
+	connectUnaryProtoContentType  = "application/proto"
+	connectUnaryJSONContentType   = "application/json"
+	connectStreamProtoContentType = "application/connect+proto"
+	connectStreamJSONContentType  = "application/connect+json"
+)
+
+const connectEndStreamFlag = 0x02
+
+// connectCall is a Connect request: its codec and whether it streams.
+type connectCall struct {
+	fullMethod string
+	stream     bool
+	json       bool
+	// input and output are resolved for JSON calls, which are converted to
+	// and from protobuf here.
+	input, output protoreflect.MessageType
+}
+
+// connectCallOf recognises Connect requests by content type, or for unary GET
+// requests by the connect=v1 query parameter. GET is only served for methods
+// declared with idempotency_level = NO_SIDE_EFFECTS (see allowsGET).
+func connectCallOf(req *http.Request) (*connectCall, bool) {
+	call := &connectCall{fullMethod: req.URL.Path}
+	if req.Method == http.MethodGet {
+		q := req.URL.Query()
+		if q.Get("connect") != "v1" {
+			return nil, false
+		}
+		call.json = q.Get("encoding") == "json"
+		return call, true
+	}
+
+	mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mt {
+	case connectUnaryProtoContentType:
+	case connectUnaryJSONContentType:
+		call.json = true
+	case connectStreamProtoContentType:
+		call.stream = true
+	case connectStreamJSONContentType:
+		call.stream, call.json = true, true
+	default:
+		return nil, false
+	}
+	return call, true
+}
+
+func (c *connectCall) contentType() string {
+	switch {
+	case c.stream && c.json:
+		return connectStreamJSONContentType
+	case c.stream:
+		return connectStreamProtoContentType
+	case c.json:
+		return connectUnaryJSONContentType
+	}
+	return connectUnaryProtoContentType
+}
+
+// prepare validates the request headers and resolves JSON message types.
+func (c *connectCall) prepare(req *http.Request) *status.Status {
+	if v := req.Header.Get("Connect-Protocol-Version"); v != "" && v != "1" {
+		return status.Newf(codes.InvalidArgument, "unsupported Connect protocol version %q", v)
+	}
+	for _, h := range []string{"Content-Encoding", "Connect-Content-Encoding"} {
+		if enc := req.Header.Get(h); enc != "" && enc != "identity" {
+			return status.Newf(codes.Unimplemented, "compression %q is not supported", enc)
+		}
+	}
+	if req.Method == http.MethodGet {
+		if enc := req.URL.Query().Get("compression"); enc != "" && enc != "identity" {
+			return status.Newf(codes.Unimplemented, "compression %q is not supported", enc)
+		}
+	}
+	if !c.json {
+		return nil
+	}
+	md, ok := lookupMethod(c.fullMethod)
+	if !ok {
+		return status.Newf(codes.Unimplemented, "unknown method %s", c.fullMethod)
+	}
+	c.input, c.output = messageType(md.Input()), messageType(md.Output())
+	return nil
+}
+
+// lookupMethod finds the descriptor of "/pkg.Service/Method".
+func lookupMethod(fullMethod string) (protoreflect.MethodDescriptor, bool) {
+	service, method := splitMethod(fullMethod)
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, false
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, false
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	return md, md != nil
+}
+
+// allowsGET reports whether md is declared free of side effects, which the
+// Connect protocol requires of methods called with GET.
+func allowsGET(md protoreflect.MethodDescriptor) bool {
+	opts, _ := md.Options().(*descriptorpb.MethodOptions)
+	return opts.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS
+}
+
+// grpcRequest forwards req as a gRPC call marked as coming from the proxy.
+func (c *connectCall) grpcRequest(req *http.Request, body io.Reader) *http.Request {
+	grpcReq := newGRPCRequest(req, c.fullMethod, body)
+	grpcReq.Header.Set(grpcWebHeader, "1")
+	if ms, err := strconv.ParseInt(req.Header.Get("Connect-Timeout-Ms"), 10, 64); err == nil && ms > 0 {
+		if ms < 1e8 { // gRPC timeouts have at most 8 digits
+			grpcReq.Header.Set("Grpc-Timeout", strconv.FormatInt(ms, 10)+"m")
+		} else {
+			grpcReq.Header.Set("Grpc-Timeout", strconv.FormatInt((ms+999)/1000, 10)+"S")
+		}
+	}
+	return grpcReq
+}
+
+func (w WebProxy) serveConnect(resp http.ResponseWriter, req *http.Request, call *connectCall) {
+	logger.Info("WebProxy.ServeHTTP: ", zap.String("Url", req.URL.Path), zap.String("protocol", "connect"))
+	if call.stream {
+		w.serveConnectStream(resp, req, call)
+	} else {
+		w.serveConnectUnary(resp, req, call)
+	}
+}
+
+func (w WebProxy) serveConnectUnary(resp http.ResponseWriter, req *http.Request, call *connectCall) {
+	if st := w.checkMethod(call.fullMethod); st != nil {
+		writeConnectUnaryError(resp, st)
+		return
+	}
+	if md, ok := lookupMethod(call.fullMethod); ok && req.Method == http.MethodGet && !allowsGET(md) {
+		resp.Header().Set("Allow", http.MethodPost)
+		body, _ := json.Marshal(newConnectError(status.Newf(codes.Unimplemented, "%s has side effects and can't be called with GET", call.fullMethod)))
+		resp.Header().Set("Content-Type", "application/json")
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = resp.Write(body)
+		return
+	}
+	if st := call.prepare(req); st != nil {
+		writeConnectUnaryError(resp, st)
+		return
+	}
+	payload, st := connectUnaryPayload(req)
+	if st == nil && call.json {
+		payload, st = jsonToProto(call.input, payload)
+	}
+	if st != nil {
+		writeConnectUnaryError(resp, st)
+		return
+	}
+
+	buf := newGRPCResponseBuffer()
+	w.handler.ServeHTTP(buf, call.grpcRequest(req, bytes.NewReader(grpcFrame(payload))))
+
+	buf.copyHeaders(resp.Header())
+	for k, vv := range trailerMetadata(buf.header) {
+		for _, v := range vv {
+			resp.Header().Add("Trailer-"+k, v)
+		}
+	}
+	if st := buf.status(); st.Code() != codes.OK {
+		writeConnectUnaryError(resp, st)
+		return
+	}
+
+	out, err := buf.payload()
+	if err == nil && call.json {
+		out, err = protoToJSON(call.output, out)
+	}
+	if err != nil {
+		writeConnectUnaryError(resp, status.New(codes.Internal, err.Error()))
+		return
+	}
+	resp.Header().Set("Content-Type", call.contentType())
+	resp.WriteHeader(http.StatusOK)
+	_, _ = resp.Write(out)
+}
+
+// connectUnaryPayload reads the request message from the body, or from the
+// message query parameter of a GET request.
+func connectUnaryPayload(req *http.Request) ([]byte, *status.Status) {
+	if req.Method == http.MethodGet {
+		q := req.URL.Query()
+		msg := q.Get("message")
+		if q.Get("base64") != "1" {
+			return []byte(msg), nil
+		}
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(msg, "="))
+		if err != nil {
+			return nil, status.Newf(codes.InvalidArgument, "invalid base64 message: %v", err)
+		}
+		return b, nil
+	}
+
+	payload, err := io.ReadAll(req.Body)
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes):
+		return nil, status.Newf(codes.ResourceExhausted, "request body exceeds %d bytes", maxBytes.Limit)
+	case err != nil:
+		return nil, status.Newf(codes.InvalidArgument, "reading request body: %v", err)
+	}
+	return payload, nil
+}
+
+var connectUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+func jsonToProto(mt protoreflect.MessageType, payload []byte) ([]byte, *status.Status) {
+	msg := mt.New().Interface()
+	if len(bytes.TrimSpace(payload)) > 0 {
+		if err := connectUnmarshal.Unmarshal(payload, msg); err != nil {
+			return nil, status.Newf(codes.InvalidArgument, "invalid JSON message: %v", err)
+		}
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, status.New(codes.Internal, err.Error())
+	}
+	return b, nil
+}
+
+func protoToJSON(mt protoreflect.MessageType, payload []byte) ([]byte, error) {
+	msg := mt.New().Interface()
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return nil, err
+	}
+	return protojson.Marshal(msg)
+}
+
+// connectError is the JSON form of an error in the Connect protocol.
+type connectError struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message,omitempty"`
+	Details []connectDetail `json:"details,omitempty"`
+}
+
+type connectDetail struct {
+	Type  string `json:"type"`
+	Value string `json:"value"` // base64, unpadded
+}
+
+func newConnectError(st *status.Status) *connectError {
+	e := &connectError{Code: connectCode(st.Code()), Message: st.Message()}
+	for _, d := range st.Proto().GetDetails() {
+		e.Details = append(e.Details, connectDetail{
+			Type:  d.GetTypeUrl()[strings.LastIndex(d.GetTypeUrl(), "/")+1:],
+			Value: base64.RawStdEncoding.EncodeToString(d.GetValue()),
+		})
+	}
+	return e
+}
+
+// connectCode is the snake_case name Connect uses for c.
+func connectCode(c codes.Code) string {
+	if c == codes.Canceled {
+		return "canceled"
+	}
+	return strings.ToLower(code.Code(c).String())
+}
+
+func writeConnectUnaryError(resp http.ResponseWriter, st *status.Status) {
+	body, _ := json.Marshal(newConnectError(st))
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(httpStatusFromCode(st.Code()))
+	_, _ = resp.Write(body)
+}
+
+func (w WebProxy) serveConnectStream(resp http.ResponseWriter, req *http.Request, call *connectCall) {
+	sw := &connectStreamResponse{wrapped: resp, header: http.Header{}, call: call}
+	st := w.checkMethod(call.fullMethod)
+	if st == nil {
+		st = call.prepare(req)
+	}
+	if st != nil {
+		sw.finish(st)
+		return
+	}
+
+	var body io.Reader = req.Body // connect+proto envelopes are gRPC frames
+	if call.json {
+		body = &connectJSONReader{src: req.Body, input: call.input}
+	}
+	w.handler.ServeHTTP(sw, call.grpcRequest(req, body))
+	sw.finish(grpcStatus(sw.header, nil))
+}
+
+// connectJSONReader turns connect+json envelopes into gRPC frames.
+type connectJSONReader struct {
+	src   io.Reader
+	input protoreflect.MessageType
+	buf   bytes.Buffer
+	err   error
+}
+
+func (r *connectJSONReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	return r.buf.Read(p)
+}
+
+func (r *connectJSONReader) next() error {
+	var hdr [5]byte
+	if _, err := io.ReadFull(r.src, hdr[:]); err != nil {
+		return err // io.EOF ends the stream
+	}
+	if hdr[0]&0x01 != 0 {
+		return errors.New("compressed Connect message")
+	}
+	var payload bytes.Buffer
+	// copied, not preallocated, so a forged length cannot exhaust memory
+	if _, err := io.CopyN(&payload, r.src, int64(binary.BigEndian.Uint32(hdr[1:5]))); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	b, st := jsonToProto(r.input, payload.Bytes())
+	if st != nil {
+		return st.Err()
+	}
+	r.buf.Write(grpcFrame(b))
+	return nil
+}
+
+// connectStreamResponse writes the gRPC response as Connect envelopes,
+// ending with an end-stream message carrying the status and trailers.
+type connectStreamResponse struct {
+	wrapped      http.ResponseWriter
+	header       http.Header // written by the gRPC server
+	call         *connectCall
+	wroteHeaders bool
+	pending      []byte // partial gRPC frame, for JSON conversion
+	err          error
+}
+
+func (w *connectStreamResponse) Header() http.Header { return w.header }
+func (w *connectStreamResponse) WriteHeader(int)     {}
+
+func (w *connectStreamResponse) Write(b []byte) (int, error) {
+	w.writeHeaders()
+	if w.err != nil {
+		return 0, w.err
+	}
+	if !w.call.json {
+		return w.wrapped.Write(b)
+	}
+
+	w.pending = append(w.pending, b...)
+	for len(w.pending) >= 5 {
+		n := int(binary.BigEndian.Uint32(w.pending[1:5]))
+		if len(w.pending) < 5+n {
+			break
+		}
+		out, err := protoToJSON(w.call.output, w.pending[5:5+n])
+		if err != nil {
+			w.err = err
+			return 0, err
+		}
+		w.pending = w.pending[5+n:]
+		if err := w.envelope(0, out); err != nil {
+			w.err = err
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *connectStreamResponse) Flush() {
+	w.writeHeaders()
+	flushWriter(w.wrapped)
+}
+
+func (w *connectStreamResponse) writeHeaders() {
+	if w.wroteHeaders {
+		return
+	}
+	w.wroteHeaders = true
+	copyMetadataHeaders(w.wrapped.Header(), w.header)
+	w.wrapped.Header().Set("Content-Type", w.call.contentType())
+	w.wrapped.WriteHeader(http.StatusOK)
+}
+
+func (w *connectStreamResponse) envelope(flags byte, payload []byte) error {
+	hdr := []byte{flags, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(hdr[1:5], uint32(len(payload)))
+	if _, err := w.wrapped.Write(hdr); err != nil {
+		return err
+	}
+	_, err := w.wrapped.Write(payload)
+	return err
+}
+
+// finish writes the end-stream message.
+func (w *connectStreamResponse) finish(st *status.Status) {
+	w.writeHeaders()
+	if w.err != nil && st.Code() == codes.OK {
+		st = status.New(codes.Internal, fmt.Sprintf("encoding response: %v", w.err))
+	}
+	end := struct {
+		Error    *connectError       `json:"error,omitempty"`
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}{Metadata: trailerMetadata(w.header)}
+	if st.Code() != codes.OK {
+		end.Error = newConnectError(st)
+	}
+	body, _ := json.Marshal(end)
+	_ = w.envelope(connectEndStreamFlag, body)
+	flushWriter(w.wrapped)
+}
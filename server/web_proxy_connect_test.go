@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func libraryProxy(t *testing.T, reply func(string) (string, error)) (WebProxy, *[]libraryCall) {
+	srv := grpc.NewServer()
+	calls := &[]libraryCall{}
+	registerLibrary(t, calls, reply)(srv, struct{}{})
+	return GetWebProxy(srv), calls
+}
+
+func bookReply(string) (string, error) { return `{"name":"shelves/1/books/2","title":"Dune"}`, nil }
+
+func connectEnvelope(flags byte, payload string) []byte {
+	b := []byte{flags, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b[1:], uint32(len(payload)))
+	return append(b, payload...)
+}
+
+// readEnvelopes splits a Connect streaming body.
+func readEnvelopes(t *testing.T, body []byte) (flags []byte, payloads []string) {
+	t.Helper()
+	for len(body) > 0 {
+		if !assert.GreaterOrEqual(t, len(body), 5) {
+			return
+		}
+		n := int(binary.BigEndian.Uint32(body[1:5]))
+		flags = append(flags, body[0])
+		payloads = append(payloads, string(body[5:5+n]))
+		body = body[5+n:]
+	}
+	return flags, payloads
+}
+
+func TestConnect_UnaryJSON(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	req := httptest.NewRequest(http.MethodPost, "/transcodingtest.Library/GetBook", strings.NewReader(`{"name":"shelves/1/books/2","full":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connect-Protocol-Version", "1")
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"name":"shelves/1/books/2","title":"Dune"}`, rec.Body.String())
+	assert.Equal(t, "library", rec.Header().Get("Trailer-X-Served-By"))
+	assert.JSONEq(t, `{"name":"shelves/1/books/2","full":true}`, (*calls)[0].in)
+}
+
+func TestConnect_UnaryProto(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	md := librarySvc(t).Methods().ByName("GetBook")
+	in := dynamicpb.NewMessage(md.Input())
+	assert.NoError(t, protojson.Unmarshal([]byte(`{"name":"n1"}`), in))
+	payload, _ := proto.Marshal(in)
+
+	req := httptest.NewRequest(http.MethodPost, "/transcodingtest.Library/GetBook", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/proto")
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/proto", rec.Header().Get("Content-Type"))
+	out := dynamicpb.NewMessage(md.Output())
+	assert.NoError(t, proto.Unmarshal(rec.Body.Bytes(), out))
+	assert.Equal(t, "Dune", out.Get(md.Output().Fields().ByName("title")).String())
+	assert.JSONEq(t, `{"name":"n1"}`, (*calls)[0].in)
+}
+
+func TestConnect_UnaryGET(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	q := url.Values{"connect": {"v1"}, "encoding": {"json"}, "message": {`{"name":"n1"}`}}
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/transcodingtest.Library/GetBook?"+q.Encode(), nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"name":"n1"}`, (*calls)[0].in)
+
+	rec = httptest.NewRecorder()
+	wp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/transcodingtest.Library/ArchiveBook?"+q.Encode(), nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "only methods without side effects can be called with GET")
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	assert.Contains(t, rec.Body.String(), `"code":"unimplemented"`)
+	assert.Len(t, *calls, 1)
+}
+
+func TestConnect_UnaryError(t *testing.T) {
+	wp, _ := libraryProxy(t, func(string) (string, error) {
+		st, _ := status.New(codes.ResourceExhausted, "slow down").
+			WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)})
+		return "", st.Err()
+	})
+	req := httptest.NewRequest(http.MethodPost, "/transcodingtest.Library/GetBook", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var got connectError
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "resource_exhausted", got.Code)
+	assert.Equal(t, "slow down", got.Message)
+	if assert.Len(t, got.Details, 1) {
+		assert.Equal(t, "google.rpc.RetryInfo", got.Details[0].Type)
+	}
+}
+
+func TestConnect_RejectsUnsupportedRequests(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	serve := func(contentType, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+		req.Header = header
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		wp.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("application/json", "/transcodingtest.Library/GetBook", http.Header{"Content-Encoding": {"gzip"}})
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+
+	rec = serve("application/json", "/transcodingtest.Library/Nope", http.Header{})
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"unimplemented"`)
+
+	rec = serve("application/json", "/transcodingtest.Library/GetBook", http.Header{"Connect-Protocol-Version": {"2"}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, *calls)
+}
+
+func TestConnect_ServerStreamJSON(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	req := httptest.NewRequest(http.MethodPost, "/transcodingtest.Library/WatchBooks",
+		bytes.NewReader(connectEnvelope(0, `{"name":"n1"}`)))
+	req.Header.Set("Content-Type", "application/connect+json")
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/connect+json", rec.Header().Get("Content-Type"))
+	flags, payloads := readEnvelopes(t, rec.Body.Bytes())
+	assert.Equal(t, []byte{0, 0, connectEndStreamFlag}, flags)
+	assert.JSONEq(t, `{"name":"shelves/1/books/2","title":"Dune"}`, payloads[0])
+	assert.JSONEq(t, `{"metadata":{"x-served-by":["library"]}}`, payloads[2])
+	assert.JSONEq(t, `{"name":"n1"}`, (*calls)[0].in)
+}
+
+func TestConnect_ServerStreamProtoError(t *testing.T) {
+	wp, _ := libraryProxy(t, func(string) (string, error) { return "", status.Error(codes.NotFound, "no such shelf") })
+	md := librarySvc(t).Methods().ByName("WatchBooks")
+	payload, _ := proto.Marshal(dynamicpb.NewMessage(md.Input()))
+	req := httptest.NewRequest(http.MethodPost, "/transcodingtest.Library/WatchBooks",
+		bytes.NewReader(connectEnvelope(0, string(payload))))
+	req.Header.Set("Content-Type", "application/connect+proto")
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "stream errors are reported in the end-stream message")
+	body, _ := io.ReadAll(rec.Body)
+	flags, payloads := readEnvelopes(t, body)
+	assert.Equal(t, []byte{connectEndStreamFlag}, flags)
+	assert.JSONEq(t, `{"error":{"code":"not_found","message":"no such shelf"},"metadata":{"x-served-by":["library"]}}`, payloads[0])
+}
+
+func TestConnectCode(t *testing.T) {
+	assert.Equal(t, "canceled", connectCode(codes.Canceled))
+	assert.Equal(t, "invalid_argument", connectCode(codes.InvalidArgument))
+	assert.Equal(t, "unauthenticated", connectCode(codes.Unauthenticated))
+}
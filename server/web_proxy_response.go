@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/http2"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/grpclog"
 )
 
@@ -90,6 +93,29 @@ func (w *webProxyResponse) finishRequest(req *http.Request) {
 	}
 }
 
+// writeStatus answers without calling the gRPC server: an empty response
+// whose trailer frame carries code and msg.
+func (w *webProxyResponse) writeStatus(code codes.Code, msg string) {
+	w.headers.Set("Content-Type", grpcContentType)
+	w.WriteHeader(http.StatusOK)
+	w.headers.Set(http2.TrailerPrefix+"Grpc-Status", strconv.Itoa(int(code)))
+	w.headers.Set(http2.TrailerPrefix+"Grpc-Message", encodeGRPCMessage(msg))
+	w.copyTrailersToPayload()
+}
+
+// encodeGRPCMessage percent-encodes msg as gRPC requires for grpc-message.
+func encodeGRPCMessage(msg string) string {
+	var sb strings.Builder
+	for _, c := range []byte(msg) {
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
 func (w *webProxyResponse) copyTrailersToPayload() {
 	trailers := extractTrailingHeaders(w.headers, w.wrapped.Header())
 	trailerBuffer := new(bytes.Buffer)
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Dashboards subscribe to server-streaming methods with an EventSource on the
+// method's proxy path. The request message is JSON, in the "message" query
+// parameter (or the body of a POST). As with Connect, GET is only served for
+// methods declared with idempotency_level = NO_SIDE_EFFECTS; an EventSource
+// can only GET, so other methods need a fetch-based client that POSTs. Every response message becomes an event
+// whose data is protojson and whose id counts the messages; the call ends with
+// a "status" event carrying the Connect form of the gRPC status plus the
+// trailer metadata. A comment line is sent as a heartbeat while the stream is
+// idle.
+//
+// A reconnecting EventSource sends Last-Event-ID, which is forwarded as
+// metadata for the method to resume from (see LastEventID); ids then continue
+// from it. The lastEventId query parameter does the same for a first request.
+//
+// An EventSource can't set headers either, so as with the WebSocket bridge's
+// bearer subprotocol, an access_token query parameter can be forwarded as
+// "Authorization: Bearer <token>" unless the request has an Authorization
+// header. That is opt-in (Builder.SSEQueryToken), since query strings end up
+// in logs; the parameter is removed from the URL either way. Cookies are
+// forwarded like the other headers.
+const (
+	sseContentType      = "text/event-stream"
+	sseLastEventIDParam = "lastEventId"
+	sseAccessTokenParam = "access_token"
+
+	defaultSSEHeartbeat = 15 * time.Second
+)
+
+// LastEventID returns the id of the last SSE event a client received, so a
+// server-streaming method called through the SSE bridge can skip what was
+// already delivered. It is "" on a first subscription and for other callers.
+//
+// Example:
+//
+//	func (s *JobService) Progress(req *pb.ProgressRequest, stream pb.Jobs_ProgressServer) error {
+//	    sent, _ := strconv.Atoi(server.LastEventID(stream.Context()))
+//	    for _, p := range s.progress(req.JobId)[sent:] {
+//	        ...
+func LastEventID(ctx context.Context) string {
+	if v := metadata.ValueFromIncomingContext(ctx, "last-event-id"); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func isSSERequest(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), sseContentType)
+}
+
+func (w WebProxy) serveSSE(resp http.ResponseWriter, req *http.Request) {
+	fullMethod := req.URL.Path
+	if st := w.checkMethod(fullMethod); st != nil {
+		writeJSONError(resp, req, http.StatusNotImplemented, st)
+		return
+	}
+	md, ok := lookupMethod(fullMethod)
+	if !ok {
+		writeJSONError(resp, req, http.StatusNotImplemented, status.Newf(codes.Unimplemented, "unknown method %s", fullMethod))
+		return
+	}
+	if !md.IsStreamingServer() || md.IsStreamingClient() {
+		writeJSONError(resp, req, http.StatusNotImplemented, status.Newf(codes.Unimplemented, "%s is not a server-streaming method", fullMethod))
+		return
+	}
+	if req.Method == http.MethodGet && !allowsGET(md) {
+		resp.Header().Set("Allow", http.MethodPost)
+		writeJSONError(resp, req, http.StatusMethodNotAllowed, status.Newf(codes.Unimplemented, "%s has side effects and can't be called with GET", fullMethod))
+		return
+	}
+	payload, st := connectUnaryPayload(req)
+	if st == nil {
+		payload, st = jsonToProto(messageType(md.Input()), payload)
+	}
+	if st != nil {
+		writeJSONError(resp, req, httpStatusFromCode(st.Code()), st)
+		return
+	}
+
+	req = req.Clone(req.Context())
+	takeSSEAccessToken(req, w.sseQueryToken)
+	lastID := req.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = req.URL.Query().Get(sseLastEventIDParam)
+		if lastID != "" {
+			req.Header.Set("Last-Event-ID", lastID)
+		}
+	}
+	events := &sseResponse{wrapped: resp, header: http.Header{}, output: messageType(md.Output()), written: time.Now()}
+	events.lastID, _ = strconv.ParseUint(lastID, 10, 64)
+
+	// the stream runs until the method returns, not for the server's WriteTimeout
+	_ = http.NewResponseController(resp).SetWriteDeadline(time.Time{})
+	resp.Header().Set("Content-Type", sseContentType)
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+	flushWriter(resp)
+
+	interval := w.sseHeartbeat
+	if interval <= 0 {
+		interval = defaultSSEHeartbeat
+	}
+	done := make(chan struct{})
+	go events.heartbeat(interval, done)
+
+	grpcReq := newGRPCRequest(req, fullMethod, bytes.NewReader(grpcFrame(payload)))
+	grpcReq.Header.Set(grpcWebHeader, "1")
+	w.handler.ServeHTTP(events, grpcReq)
+	close(done)
+	events.finish(grpcStatus(events.header, nil))
+}
+
+// takeSSEAccessToken removes the access_token query parameter from req and,
+// if accept is set, sends it as the bearer token. req must be a clone.
+func takeSSEAccessToken(req *http.Request, accept bool) {
+	q := req.URL.Query()
+	if !q.Has(sseAccessTokenParam) {
+		return
+	}
+	token := q.Get(sseAccessTokenParam)
+	q.Del(sseAccessTokenParam)
+	req.URL.RawQuery = q.Encode()
+	req.RequestURI = req.URL.RequestURI()
+	if accept && token != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// sseQueryTokenMiddleware moves the access_token of SSE requests to the
+// Authorization header before tracing, metrics and Use middleware see the URL.
+func sseQueryTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSSERequest(r) {
+			r = r.Clone(r.Context())
+			takeSSEAccessToken(r, true)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sseResponse turns the gRPC server's response frames into events. Writes
+// come from the gRPC server and the heartbeat.
+type sseResponse struct {
+	mu      sync.Mutex
+	wrapped http.ResponseWriter
+	header  http.Header // written by the gRPC server
+	output  protoreflect.MessageType
+	lastID  uint64
+	pending []byte // partial gRPC frame
+	err     error
+	written time.Time // of the last event or heartbeat
+}
+
+func (w *sseResponse) Header() http.Header { return w.header }
+func (w *sseResponse) WriteHeader(int)     {}
+
+func (w *sseResponse) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	w.pending = append(w.pending, b...)
+	for len(w.pending) >= 5 {
+		n := int(binary.BigEndian.Uint32(w.pending[1:5]))
+		if len(w.pending) < 5+n {
+			break
+		}
+		data, err := protoToJSON(w.output, w.pending[5:5+n])
+		if err == nil {
+			w.lastID++
+			err = w.event(strconv.FormatUint(w.lastID, 10), "", data)
+		}
+		if err != nil {
+			w.err = err
+			return 0, err
+		}
+		w.pending = w.pending[5+n:]
+	}
+	return len(b), nil
+}
+
+func (w *sseResponse) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	flushWriter(w.wrapped)
+}
+
+// event writes one event; id and name are omitted when empty.
+func (w *sseResponse) event(id, name string, data []byte) error {
+	var buf bytes.Buffer
+	if id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", id)
+	}
+	if name != "" {
+		fmt.Fprintf(&buf, "event: %s\n", name)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+	w.written = time.Now()
+	_, err := w.wrapped.Write(buf.Bytes())
+	return err
+}
+
+// heartbeat keeps idle streams open through proxies until done is closed: a
+// comment is sent once nothing has been written for interval.
+func (w *sseResponse) heartbeat(interval time.Duration, done <-chan struct{}) {
+	t := time.NewTimer(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			w.mu.Lock()
+			idle := time.Since(w.written)
+			if idle >= interval && w.err == nil {
+				w.written = time.Now()
+				_, w.err = w.wrapped.Write([]byte(": heartbeat\n\n"))
+				flushWriter(w.wrapped)
+				idle = 0
+			}
+			w.mu.Unlock()
+			t.Reset(interval - idle)
+		}
+	}
+}
+
+// finish writes the status event.
+func (w *sseResponse) finish(st *status.Status) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil && st.Code() == codes.OK {
+		st = status.New(codes.Internal, fmt.Sprintf("encoding response: %v", w.err))
+	}
+	end := struct {
+		*connectError
+		Metadata map[string][]string `json:"metadata,omitempty"`
+	}{newConnectError(st), trailerMetadata(w.header)}
+	data, _ := json.Marshal(end)
+	_ = w.event("", "status", data)
+	flushWriter(w.wrapped)
+}
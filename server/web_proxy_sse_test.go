@@ -0,0 +1,242 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serveSSE subscribes to WatchBooks with message as the request and returns
+// the response and its events, without the trailing blank line.
+func serveSSE(wp WebProxy, message string, header http.Header) (*httptest.ResponseRecorder, []string) {
+	req := httptest.NewRequest(http.MethodGet, "/transcodingtest.Library/WatchBooks?"+url.Values{"message": {message}}.Encode(), nil)
+	for k, vv := range header {
+		req.Header[k] = vv
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, req)
+	return rec, strings.Split(strings.TrimSuffix(rec.Body.String(), "\n\n"), "\n\n")
+}
+
+func TestSSE_StreamsEventsAndStatus(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	rec, events := serveSSE(wp, `{"name":"n1"}`, nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{
+		`id: 1` + "\n" + `data: {"name":"shelves/1/books/2","title":"Dune"}`,
+		`id: 2` + "\n" + `data: {"name":"shelves/1/books/2","title":"Dune"}`,
+		`event: status` + "\n" + `data: {"code":"ok","metadata":{"x-served-by":["library"]}}`,
+	}, normalizeSpaces(events))
+	assert.JSONEq(t, `{"name":"n1"}`, (*calls)[0].in)
+}
+
+// normalizeSpaces undoes protojson's randomized whitespace.
+func normalizeSpaces(events []string) []string {
+	for i, e := range events {
+		events[i] = strings.ReplaceAll(e, `": `, `":`)
+		events[i] = strings.ReplaceAll(events[i], `, "`, `,"`)
+	}
+	return events
+}
+
+func TestSSE_ErrorStatus(t *testing.T) {
+	wp, _ := libraryProxy(t, func(string) (string, error) { return "", status.Error(codes.NotFound, "no such shelf") })
+	rec, events := serveSSE(wp, "", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code, "the stream has started; the status is an event")
+	assert.Equal(t, []string{`event: status` + "\n" + `data: {"code":"not_found","message":"no such shelf","metadata":{"x-served-by":["library"]}}`}, events)
+}
+
+func TestSSE_LastEventID(t *testing.T) {
+	var got []string
+	srv := grpc.NewServer(grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		got = append(got, LastEventID(ss.Context()))
+		return handler(srv, ss)
+	}))
+	registerLibrary(t, &[]libraryCall{}, bookReply)(srv, struct{}{})
+	wp := GetWebProxy(srv)
+
+	_, events := serveSSE(wp, "", nil)
+	assert.True(t, strings.HasPrefix(events[0], "id: 1\n"))
+
+	_, events = serveSSE(wp, "", http.Header{"Last-Event-Id": {"7"}})
+	assert.True(t, strings.HasPrefix(events[0], "id: 8\n"))
+	assert.True(t, strings.HasPrefix(events[1], "id: 9\n"))
+
+	req := httptest.NewRequest(http.MethodGet, "/transcodingtest.Library/WatchBooks?lastEventId=3", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	wp.ServeHTTP(rec, req)
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "id: 4\n"))
+
+	assert.Equal(t, []string{"", "7", "3"}, got)
+}
+
+func TestSSE_AccessToken(t *testing.T) {
+	var got []string
+	srv := grpc.NewServer(grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		got = append(got, strings.Join(md.Get("authorization"), ","))
+		return handler(srv, ss)
+	}))
+	registerLibrary(t, &[]libraryCall{}, bookReply)(srv, struct{}{})
+	wp := GetWebProxy(srv)
+	serve := func(query string, header http.Header) {
+		req := httptest.NewRequest(http.MethodGet, "/transcodingtest.Library/WatchBooks?"+query, nil)
+		for k, vv := range header {
+			req.Header[k] = vv
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		wp.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("access_token=t0k", nil)
+	wp.sseQueryToken = true
+	serve("access_token=t0k", nil)
+	serve("access_token=ignored", http.Header{"Authorization": {"Bearer hdr"}})
+	serve("", nil)
+
+	assert.Equal(t, []string{"", "Bearer t0k", "Bearer hdr", ""}, got, "opt-in, and a header wins over the query parameter")
+}
+
+func TestTakeSSEAccessToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/svc/Watch?message=%7B%7D&access_token=t0k", nil)
+	takeSSEAccessToken(req, false)
+	assert.Equal(t, "message=%7B%7D", req.URL.RawQuery)
+	assert.Equal(t, "/svc/Watch?message=%7B%7D", req.RequestURI)
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestBuilder_SSEQueryToken(t *testing.T) {
+	var seen []string
+	bs, err := New().
+		HTTPPort(":0").
+		SSEQueryToken().
+		Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = append(seen, r.URL.RawQuery, r.Header.Get("Authorization"))
+				next.ServeHTTP(w, r)
+			})
+		}).
+		RegisterService(registerLibrary(t, &[]libraryCall{}, bookReply), func() *struct{} { return &struct{}{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/transcodingtest.Library/WatchBooks?access_token=t0k", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	bs.http.Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"", "Bearer t0k"}, seen, "middleware never sees the token in the URL")
+}
+
+func TestSSE_Heartbeat(t *testing.T) {
+	wp, _ := libraryProxy(t, func(string) (string, error) {
+		time.Sleep(50 * time.Millisecond)
+		return bookReply("")
+	})
+	wp.sseHeartbeat = 10 * time.Millisecond
+	_, events := serveSSE(wp, "", nil)
+
+	assert.Equal(t, ": heartbeat", events[0])
+	assert.True(t, strings.HasPrefix(events[len(events)-1], "event: status\n"))
+}
+
+func TestSSEResponse_HeartbeatOnlyWhenIdle(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &sseResponse{wrapped: rec, written: time.Now()}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		w.heartbeat(100*time.Millisecond, done)
+		close(stopped)
+	}()
+
+	for i := 0; i < 5; i++ {
+		time.Sleep(40 * time.Millisecond)
+		w.mu.Lock()
+		_ = w.event("", "", []byte("{}"))
+		w.mu.Unlock()
+	}
+	w.mu.Lock()
+	assert.NotContains(t, rec.Body.String(), ": heartbeat", "events reset the heartbeat")
+	w.mu.Unlock()
+
+	time.Sleep(150 * time.Millisecond)
+	close(done)
+	<-stopped
+	assert.Contains(t, rec.Body.String(), ": heartbeat")
+}
+
+func TestSSE_RejectsBadRequests(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		rec := httptest.NewRecorder()
+		wp.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/transcodingtest.Library/GetBook")
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+	assert.Equal(t, "UNIMPLEMENTED", decodeEnvelope(t, rec).Status)
+
+	rec = serve("/transcodingtest.Library/WatchBooks?message=%7Bnope")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	assert.Equal(t, http.StatusNotImplemented, serve("/transcodingtest.Library/Nope").Code)
+	assert.Empty(t, *calls)
+}
+
+func TestSSE_GETNeedsNoSideEffects(t *testing.T) {
+	wp, calls := libraryProxy(t, bookReply)
+	serve := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/transcodingtest.Library/ReindexBooks", strings.NewReader(`{"name":"n1"}`))
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		wp.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodGet)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	assert.Equal(t, "UNIMPLEMENTED", decodeEnvelope(t, rec).Status)
+	assert.Empty(t, *calls)
+
+	rec = serve(http.MethodPost)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, *calls, 1)
+}
+
+func TestLastEventID(t *testing.T) {
+	assert.Equal(t, "", LastEventID(context.Background()))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("last-event-id", "42"))
+	assert.Equal(t, "42", LastEventID(ctx))
+}
+
+func TestBuilder_SSEHeartbeat(t *testing.T) {
+	assert.Equal(t, time.Second, New().SSEHeartbeat(time.Second).sseHeartbeat)
+	mockLogger := withMockLogger(func() { New().SSEHeartbeat(0) })
+	assert.True(t, mockLogger.isFatalCalled)
+}
@@ -3,10 +3,13 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,8 +58,8 @@ func TestWebProxyServeHTTP(t *testing.T) {
 
 	// Directly construct WebProxy with the spy handler.
 	wp := WebProxy{
-		handler:       spy,
-		endPointsFunc: func() []string { return []string{"/test"} },
+		handler:   spy,
+		endPoints: map[string]bool{"/test": true},
 	}
 
 	req := httptest.NewRequest(http.MethodGet, "/test", nil)
@@ -68,6 +71,132 @@ func TestWebProxyServeHTTP(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rec.Code, "expected status 200")
 }
 
+func TestWebProxyServeHTTP_UnknownMethod(t *testing.T) {
+	spy := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { t.Error("unknown method reached the gRPC server") })
+	wp := WebProxy{handler: spy, endPoints: map[string]bool{"/test.TestService/Ping": true}}
+
+	for _, contentType := range []string{grpcWebContentType, grpcWebTextContentType} {
+		req := httptest.NewRequest(http.MethodPost, "/test.TestService/Pong", nil)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		wp.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+		body := rec.Body.Bytes()
+		if contentType == grpcWebTextContentType {
+			body, _ = base64.StdEncoding.DecodeString(rec.Body.String())
+		}
+		if assert.Greater(t, len(body), 5) {
+			assert.Equal(t, byte(1<<7), body[0], "trailer frame")
+			assert.Contains(t, string(body[5:]), "grpc-status: 12\r\n")
+			assert.Contains(t, string(body[5:]), "grpc-message: unknown method /test.TestService/Pong\r\n")
+		}
+	}
+}
+
+func TestWebProxyServeHTTP_UnknownMethodInEveryProtocol(t *testing.T) {
+	spy := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { t.Error("unknown method reached the gRPC server") })
+	wp := WebProxy{handler: spy, endPoints: map[string]bool{"/test.TestService/Ping": true}}
+	serve := func(method string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/test.TestService/Pong?connect=v1", strings.NewReader("{}"))
+		for k, vv := range header {
+			req.Header[k] = vv
+		}
+		rec := httptest.NewRecorder()
+		wp.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPost, http.Header{"Content-Type": {"application/json"}})
+	assert.Equal(t, http.StatusNotImplemented, rec.Code, "Connect unary")
+	assert.Contains(t, rec.Body.String(), `"code":"unimplemented"`)
+
+	rec = serve(http.MethodGet, nil)
+	assert.Equal(t, http.StatusNotImplemented, rec.Code, "Connect GET")
+
+	rec = serve(http.MethodPost, http.Header{"Content-Type": {"application/connect+proto"}})
+	assert.Contains(t, rec.Body.String(), `"code":"unimplemented"`, "Connect streaming")
+
+	rec = serve(http.MethodGet, http.Header{"Accept": {"text/event-stream"}})
+	assert.Equal(t, http.StatusNotImplemented, rec.Code, "SSE")
+
+	ts := httptest.NewServer(wp)
+	defer ts.Close()
+	conn := dialWebSocket(t, ts, "/test.TestService/Pong", nil)
+	_, _ = wsRead(t, conn)
+	_, trailers := wsRead(t, conn)
+	assert.Contains(t, trailers, "grpc-status: 12\r\n", "WebSocket")
+}
+
+func TestEncodeGRPCMessage(t *testing.T) {
+	assert.Equal(t, "unknown method /a.B/C", encodeGRPCMessage("unknown method /a.B/C"))
+	assert.Equal(t, "100%25 caf%C3%A9%0A", encodeGRPCMessage("100% café\n"))
+}
+
+func TestBuilder_WebProxyRoutes(t *testing.T) {
+	serve := func(bs *BootServer, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Content-Type", grpcWebContentType)
+		rec := httptest.NewRecorder()
+		bs.http.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+	health := "/grpc.health.v1.Health/"
+
+	bs, err := New().HTTPPort(":0").Build()
+	assert.NoError(t, err)
+	defer bs.lnHTTP.Close()
+
+	rec := serve(bs, http.MethodPost, "/api"+health+"Nope")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "grpc-status: 12")
+	assert.Equal(t, http.StatusNotFound, serve(bs, http.MethodPost, health+"Check").Code)
+	assert.Equal(t, http.StatusNotFound, serve(bs, http.MethodPost, "/api/unknown.Service/Check").Code)
+
+	rec = serve(bs, http.MethodGet, "/api/services")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var got struct{ Services []grpcServiceInfo }
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	if assert.Len(t, got.Services, 1) {
+		assert.Equal(t, "grpc.health.v1.Health", got.Services[0].Name)
+		assert.Contains(t, got.Services[0].Methods, grpcMethodInfo{Name: "Check", Path: "/api" + health + "Check"})
+		assert.Contains(t, got.Services[0].Methods, grpcMethodInfo{Name: "Watch", Path: "/api" + health + "Watch", ServerStreaming: true})
+	}
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(bs, http.MethodPost, "/api/services").Code)
+
+	bs, err = New().HTTPPort(":0").WebProxyPrefix("").Build()
+	assert.NoError(t, err)
+	defer bs.lnHTTP.Close()
+
+	assert.Contains(t, serve(bs, http.MethodPost, health+"Nope").Body.String(), "grpc-status: 12")
+	assert.Equal(t, http.StatusNotFound, serve(bs, http.MethodPost, "/api"+health+"Check").Code)
+	assert.Contains(t, serve(bs, http.MethodGet, "/services").Body.String(), `"path":"`+health+`Check"`)
+}
+
+func TestBuilder_WebProxyPrefix(t *testing.T) {
+	assert.Equal(t, "/grpc", New().WebProxyPrefix("/grpc/").webProxyPrefix)
+
+	for _, prefix := range []string{"grpc", "/{svc}"} {
+		mockLogger := withMockLogger(func() { New().WebProxyPrefix(prefix) })
+		assert.True(t, mockLogger.isFatalCalled, prefix)
+	}
+}
+
+func TestBuilder_WebProxyConflict(t *testing.T) {
+	_, err := New().HTTPPort(":0").WebProxyPrefix("").
+		AddRestController(func() healthRouteController { return healthRouteController{} }).
+		Build()
+	assert.ErrorContains(t, err, "web proxy failed")
+}
+
+type healthRouteController struct{}
+
+func (healthRouteController) Routes() []Route {
+	return []Route{{Pattern: "/grpc.health.v1.Health/", Handler: func(http.ResponseWriter, *http.Request) {}}}
+}
+
 func TestReaderCloser_ReadDelegates(t *testing.T) {
 	buf := bytes.NewBufferString("hello")
 	c := &mockCloser{}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/cors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// gRPC-Web can't carry client or bidi streams, so the proxy also accepts a
+// WebSocket upgrade on a method path and bridges the socket to the gRPC
+// server. Every WebSocket message is one binary frame: a flag byte, a 4-byte
+// big-endian length and the payload.
+//
+//	client → server  0x00  a request message
+//	                 0x80  end of the request stream, empty
+//	server → client  0x40  response header metadata, as an HTTP/1 header block
+//	                 0x00  a response message
+//	                 0x80  grpc-status, grpc-message and trailer metadata; last
+//
+// Metadata and credentials travel on the handshake: its headers are forwarded
+// as for gRPC-Web, and since browsers can't set headers there, a
+// "bearer.<token>" subprotocol is forwarded as "Authorization: Bearer <token>".
+// Clients offer the "grpc-ws" subprotocol, which the server selects.
+const (
+	webSocketProtocol     = "grpc-ws"
+	webSocketBearerPrefix = "bearer."
+
+	wsMessageFlag  byte = 0x00
+	wsHeadersFlag  byte = 0x40
+	wsTrailersFlag byte = 0x80
+
+	// grpc's default receive limit plus the frame header
+	wsMaxFrameBytes = 4<<20 + 5
+	wsWriteTimeout  = 10 * time.Second
+)
+
+var errWebSocketFrame = errors.New("invalid WebSocket frame")
+
+// webSocketOriginChecker accepts clients that send no Origin, same-origin
+// pages and the origins c allows.
+func webSocketOriginChecker(c *cors.Cors) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		return c.OriginAllowed(r)
+	}
+}
+
+func (w WebProxy) serveWebSocket(resp http.ResponseWriter, req *http.Request) {
+	fullMethod := req.URL.Path
+	handshake := req.Clone(req.Context())
+	for k := range handshake.Header {
+		if strings.HasPrefix(k, "Sec-Websocket-") {
+			handshake.Header.Del(k)
+		}
+	}
+	for _, p := range websocket.Subprotocols(req) {
+		if token, ok := strings.CutPrefix(p, webSocketBearerPrefix); ok && handshake.Header.Get("Authorization") == "" {
+			handshake.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	upgrader := websocket.Upgrader{Subprotocols: []string{webSocketProtocol}, CheckOrigin: w.checkOrigin}
+	conn, err := upgrader.Upgrade(resp, req, nil)
+	if err != nil {
+		return // Upgrade has replied with an HTTP error
+	}
+	defer conn.Close()
+	conn.SetReadLimit(wsMaxFrameBytes)
+	// the HTTP server's read deadline outlives the hijack
+	_ = conn.SetReadDeadline(time.Time{})
+	grpcResp := &webSocketResponse{conn: conn, header: http.Header{}}
+	if st := w.checkMethod(fullMethod); st != nil {
+		grpcResp.finish(st)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	body, requests := io.Pipe()
+	frameErr := make(chan error, 1)
+	go readWebSocketFrames(conn, requests, cancel, frameErr)
+
+	grpcReq := newGRPCRequest(handshake.WithContext(ctx), fullMethod, body)
+	grpcReq.Header.Set(grpcWebHeader, "1")
+	w.handler.ServeHTTP(grpcResp, grpcReq)
+	body.Close()
+
+	st := grpcResp.status()
+	select {
+	case err := <-frameErr:
+		st = status.New(codes.InvalidArgument, err.Error())
+	default:
+	}
+	grpcResp.finish(st)
+}
+
+// readWebSocketFrames copies request messages from conn to requests until
+// the end-of-stream frame, then waits for the socket to close. A closed
+// socket cancels the call; a malformed frame is reported on frameErr.
+func readWebSocketFrames(conn *websocket.Conn, requests *io.PipeWriter, cancel context.CancelFunc, frameErr chan<- error) {
+	defer cancel()
+	for {
+		typ, frame, err := conn.ReadMessage()
+		if err != nil {
+			requests.CloseWithError(io.ErrUnexpectedEOF)
+			return
+		}
+		if typ != websocket.BinaryMessage || len(frame) < 5 || int(binary.BigEndian.Uint32(frame[1:5])) != len(frame)-5 {
+			frameErr <- errWebSocketFrame
+			requests.CloseWithError(errWebSocketFrame)
+			return
+		}
+		switch frame[0] {
+		case wsMessageFlag:
+			if _, err := requests.Write(frame); err != nil && !errors.Is(err, io.ErrClosedPipe) {
+				return
+			}
+		case wsTrailersFlag:
+			requests.Close()
+		default:
+			frameErr <- errWebSocketFrame
+			requests.CloseWithError(errWebSocketFrame)
+			return
+		}
+	}
+}
+
+// webSocketResponse writes the gRPC server's response to the socket as
+// frames. The gRPC server writes from a single goroutine.
+type webSocketResponse struct {
+	conn        *websocket.Conn
+	header      http.Header
+	wroteHeader bool
+	pending     []byte // a partially written message
+}
+
+func (w *webSocketResponse) Header() http.Header { return w.header }
+func (w *webSocketResponse) Flush()              {}
+
+// WriteHeader sends the header metadata; the status comes with the trailers.
+func (w *webSocketResponse) WriteHeader(int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := http.Header{}
+	copyMetadataHeaders(h, w.header)
+	lower := http.Header{}
+	for k, vv := range h {
+		lower[strings.ToLower(k)] = vv
+	}
+	_ = w.send(wsHeadersFlag, headerBlock(lower))
+}
+
+func (w *webSocketResponse) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.pending = append(w.pending, b...)
+	for len(w.pending) >= 5 {
+		n := 5 + int(binary.BigEndian.Uint32(w.pending[1:5]))
+		if len(w.pending) < n {
+			break
+		}
+		if err := w.send(w.pending[0], w.pending[5:n]); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[n:]
+	}
+	return len(b), nil
+}
+
+func (w *webSocketResponse) status() *status.Status { return grpcStatus(w.header, w.pending) }
+
+// finish sends the trailers frame and closes the socket normally.
+func (w *webSocketResponse) finish(st *status.Status) {
+	w.WriteHeader(http.StatusOK)
+	trailers := http.Header(trailerMetadata(w.header))
+	trailers["grpc-status"] = []string{strconv.Itoa(int(st.Code()))}
+	if msg := st.Message(); msg != "" {
+		trailers["grpc-message"] = []string{encodeGRPCMessage(msg)}
+	}
+	if len(st.Details()) > 0 {
+		if b, err := proto.Marshal(st.Proto()); err == nil {
+			trailers["grpc-status-details-bin"] = []string{base64.RawStdEncoding.EncodeToString(b)}
+		}
+	}
+	if w.send(wsTrailersFlag, headerBlock(trailers)) != nil {
+		return
+	}
+	_ = w.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(wsWriteTimeout))
+}
+
+func (w *webSocketResponse) send(flag byte, payload []byte) error {
+	frame := make([]byte, 5+len(payload))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	_ = w.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return w.conn.WriteMessage(websocket.BinaryMessage, frame)
+}
+
+func headerBlock(h http.Header) []byte {
+	var buf bytes.Buffer
+	_ = h.Write(&buf)
+	return buf.Bytes()
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/cors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// registerEcho registers a streaming service over StringValue:
+//
+//	service Echo {
+//	  rpc Chat(stream StringValue) returns (stream StringValue) // "<x-room>: <msg>" per message
+//	  rpc Join(stream StringValue) returns (StringValue)        // the messages joined by ","
+//	}
+//
+// Both reply with header x-auth (the authorization metadata) and trailer
+// x-done; a "fail" message ends the call with FailedPrecondition.
+func registerEcho(r grpc.ServiceRegistrar, srv any) {
+	recvAll := func(ss grpc.ServerStream, each func(string) error) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs("x-auth", strings.Join(md.Get("authorization"), ",")))
+		ss.SetTrailer(metadata.Pairs("x-done", "yes"))
+		for {
+			in := &wrapperspb.StringValue{}
+			err := ss.RecvMsg(in)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if in.Value == "fail" {
+				return status.Error(codes.FailedPrecondition, "no thanks")
+			}
+			if err := each(in.Value); err != nil {
+				return err
+			}
+		}
+	}
+	r.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "wstest.Echo",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{
+			{StreamName: "Chat", ServerStreams: true, ClientStreams: true, Handler: func(_ any, ss grpc.ServerStream) error {
+				md, _ := metadata.FromIncomingContext(ss.Context())
+				return recvAll(ss, func(msg string) error {
+					return ss.SendMsg(wrapperspb.String(strings.Join(md.Get("x-room"), "") + ": " + msg))
+				})
+			}},
+			{StreamName: "Join", ClientStreams: true, Handler: func(_ any, ss grpc.ServerStream) error {
+				var msgs []string
+				if err := recvAll(ss, func(msg string) error { msgs = append(msgs, msg); return nil }); err != nil {
+					return err
+				}
+				return ss.SendMsg(wrapperspb.String(strings.Join(msgs, ",")))
+			}},
+		},
+	}, srv)
+}
+
+func echoProxy(t *testing.T) *httptest.Server {
+	srv := grpc.NewServer()
+	registerEcho(srv, struct{}{})
+	ts := httptest.NewServer(GetWebProxy(srv))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func dialWebSocket(t *testing.T, ts *httptest.Server, path string, header http.Header, protocols ...string) *websocket.Conn {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: append([]string{webSocketProtocol}, protocols...)}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+path, header)
+	if err != nil {
+		t.Fatalf("Dial() failed: %v", err)
+	}
+	assert.Equal(t, webSocketProtocol, resp.Header.Get("Sec-WebSocket-Protocol"))
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func wsSend(t *testing.T, conn *websocket.Conn, flag byte, msg string) {
+	t.Helper()
+	var payload []byte
+	if flag == wsMessageFlag {
+		payload, _ = proto.Marshal(wrapperspb.String(msg))
+	}
+	frame := append([]byte{flag, 0, 0, 0, 0}, payload...)
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+		t.Fatalf("WriteMessage() failed: %v", err)
+	}
+}
+
+// wsRead reads a frame, decoding messages as StringValue.
+func wsRead(t *testing.T, conn *websocket.Conn) (byte, string) {
+	t.Helper()
+	_, frame, err := conn.ReadMessage()
+	if err != nil || len(frame) < 5 {
+		t.Fatalf("ReadMessage() = %q, %v", frame, err)
+	}
+	if frame[0] != wsMessageFlag {
+		return frame[0], string(frame[5:])
+	}
+	msg := &wrapperspb.StringValue{}
+	assert.NoError(t, proto.Unmarshal(frame[5:], msg))
+	return frame[0], msg.Value
+}
+
+func assertWebSocketClosed(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), "got %v", err)
+}
+
+func TestWebSocket_Bidi(t *testing.T) {
+	bs, err := New().HTTPPort(":0").
+		PublicMethods("/wstest.Echo/*").
+		RegisterService(registerEcho, func() *struct{} { return &struct{}{} }).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer bs.lnHTTP.Close()
+	ts := httptest.NewServer(bs.http.Handler)
+	defer ts.Close()
+
+	conn := dialWebSocket(t, ts, "/api/wstest.Echo/Chat", http.Header{"X-Room": {"lobby"}}, "bearer.t0k")
+	for i, msg := range []string{"hi", "there"} {
+		wsSend(t, conn, wsMessageFlag, msg)
+		if i == 0 {
+			flag, headers := wsRead(t, conn) // sent with the first response
+			assert.Equal(t, wsHeadersFlag, flag)
+			assert.Contains(t, headers, "x-auth: Bearer t0k\r\n")
+		}
+		flag, got := wsRead(t, conn)
+		assert.Equal(t, wsMessageFlag, flag)
+		assert.Equal(t, "lobby: "+msg, got)
+	}
+	wsSend(t, conn, wsTrailersFlag, "")
+
+	flag, trailers := wsRead(t, conn)
+	assert.Equal(t, wsTrailersFlag, flag)
+	assert.Contains(t, trailers, "grpc-status: 0\r\n")
+	assert.Contains(t, trailers, "x-done: yes\r\n")
+	assertWebSocketClosed(t, conn)
+}
+
+func TestWebSocket_ClientStream(t *testing.T) {
+	conn := dialWebSocket(t, echoProxy(t), "/wstest.Echo/Join", http.Header{"Authorization": {"Bearer hdr"}}, "bearer.ignored")
+	for _, msg := range []string{"a", "b", "c"} {
+		wsSend(t, conn, wsMessageFlag, msg)
+	}
+	wsSend(t, conn, wsTrailersFlag, "")
+
+	_, headers := wsRead(t, conn)
+	assert.Contains(t, headers, "x-auth: Bearer hdr\r\n", "a handshake header wins over the subprotocol")
+	flag, got := wsRead(t, conn)
+	assert.Equal(t, wsMessageFlag, flag)
+	assert.Equal(t, "a,b,c", got)
+	_, trailers := wsRead(t, conn)
+	assert.Contains(t, trailers, "grpc-status: 0\r\n")
+	assertWebSocketClosed(t, conn)
+}
+
+func TestWebSocket_Errors(t *testing.T) {
+	ts := echoProxy(t)
+
+	conn := dialWebSocket(t, ts, "/wstest.Echo/Chat", nil)
+	wsSend(t, conn, wsMessageFlag, "fail")
+	_, _ = wsRead(t, conn)
+	flag, trailers := wsRead(t, conn)
+	assert.Equal(t, wsTrailersFlag, flag)
+	assert.Contains(t, trailers, "grpc-status: 9\r\n")
+	assert.Contains(t, trailers, "grpc-message: no thanks\r\n")
+	assertWebSocketClosed(t, conn)
+
+	conn = dialWebSocket(t, ts, "/wstest.Echo/Chat", nil)
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+	_, _ = wsRead(t, conn)
+	_, trailers = wsRead(t, conn)
+	assert.Contains(t, trailers, "grpc-status: 3\r\n")
+
+	conn = dialWebSocket(t, ts, "/wstest.Echo/Nope", nil)
+	_, _ = wsRead(t, conn)
+	_, trailers = wsRead(t, conn)
+	assert.Contains(t, trailers, "grpc-status: 12\r\n")
+}
+
+func TestWebSocket_ClientGoneCancelsCall(t *testing.T) {
+	done := make(chan error, 1)
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "wstest.Wait",
+		HandlerType: (*any)(nil),
+		Streams: []grpc.StreamDesc{{StreamName: "Wait", ClientStreams: true, ServerStreams: true, Handler: func(_ any, ss grpc.ServerStream) error {
+			<-ss.Context().Done()
+			done <- ss.Context().Err()
+			return nil
+		}}},
+	}, struct{}{})
+	ts := httptest.NewServer(GetWebProxy(srv))
+	defer ts.Close()
+
+	conn := dialWebSocket(t, ts, "/wstest.Wait/Wait", nil)
+	conn.Close()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWebSocketOriginChecker(t *testing.T) {
+	check := webSocketOriginChecker(cors.New(cors.Options{AllowedOrigins: []string{"https://app.example"}}))
+	req := func(origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://api.example/x.Y/Z", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+
+	assert.True(t, check(req("")))
+	assert.True(t, check(req("http://api.example")))
+	assert.True(t, check(req("https://app.example")))
+	assert.False(t, check(req("https://evil.example")))
+}
@@ -0,0 +1,15 @@
+package testutil
+
+import (
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewInMemoryTracing returns a TracerProvider that exports every span, as
+// soon as it ends, to the returned in-memory exporter. Pass the provider to
+// server.Builder.WithTracing or otel.SetTracerProvider and assert on
+// exporter.GetSpans().
+func NewInMemoryTracing() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)), exporter
+}